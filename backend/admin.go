@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"interview-prep/config"
+	"interview-prep/database"
+	"interview-prep/helpers"
+	"interview-prep/models"
+	"interview-prep/store"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const adminUsage = `usage: backend admin <command>
+
+commands:
+  create EMAIL FIRST LAST   create a verified admin; the password is read from stdin
+  promote EMAIL             make an existing user an admin
+
+Signing up never grants admin; use these to set up the first admin, who can
+then promote others through the API.`
+
+// runAdmin implements the `admin` subcommand. Changes are recorded in the
+// audit log without an actor.
+func runAdmin(cfg *config.Config, logger *slog.Logger, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+	switch {
+	case args[0] == "create" && len(args) == 4:
+	case args[0] == "promote" && len(args) == 2:
+	default:
+		fmt.Fprintln(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := database.RunMigrations(db, logger); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	pg := store.NewPostgres(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			log.Fatal("no password given on stdin")
+		}
+		password = strings.TrimRight(password, "\r\n")
+		if len(password) < 6 {
+			log.Fatal("password must be at least 6 characters long")
+		}
+		hash, err := helpers.HashPassword(password)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		u := models.User{Email: args[1], FirstName: args[2], LastName: args[3], Password: hash}
+		err = pg.CreateAdmin(ctx, 0, &u)
+		if errors.Is(err, store.ErrConflict) {
+			log.Fatalf("%s already has an account; use admin promote", u.Email)
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Created admin %s (id %d)\n", u.Email, u.ID)
+
+	case "promote":
+		u, err := pg.GetUserByEmail(ctx, args[1])
+		if errors.Is(err, store.ErrNotFound) {
+			log.Fatalf("no user with email %s", args[1])
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		if err := pg.SetUserRole(ctx, 0, u.ID, models.RoleAdmin); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s is now an admin\n", u.Email)
+	}
+}
@@ -0,0 +1,167 @@
+// Package apperr is the error model of the API. Handlers report failures as
+// *Error values with a kind, which picks the HTTP status, and a stable code
+// clients can act on; the error middleware turns them into RFC 7807
+// problem details. Any other error is an internal one whose text never
+// reaches the client.
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindTooManyRequests
+	KindUnavailable
+	KindUnprocessable
+)
+
+var statuses = map[Kind]int{
+	KindInternal:        http.StatusInternalServerError,
+	KindValidation:      http.StatusBadRequest,
+	KindUnauthorized:    http.StatusUnauthorized,
+	KindForbidden:       http.StatusForbidden,
+	KindNotFound:        http.StatusNotFound,
+	KindConflict:        http.StatusConflict,
+	KindTooLarge:        http.StatusRequestEntityTooLarge,
+	KindTooManyRequests: http.StatusTooManyRequests,
+	KindUnavailable:     http.StatusServiceUnavailable,
+	KindUnprocessable:   http.StatusUnprocessableEntity,
+}
+
+// FieldError says what is wrong with one field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Code is a stable, snake_case name for the failure.
+	Code string
+	// Message is shown to the user.
+	Message string
+	Fields  []FieldError
+	// Err is the cause, logged but never sent.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status is the HTTP status the error is answered with.
+func (e *Error) Status() int {
+	return statuses[e.Kind]
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Unavailable reports that a service the request relies on, such as the
+// grader or the identity provider, failed, so the request may succeed
+// later.
+func Unavailable(code, message string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Message: message, Err: err}
+}
+
+// BadRequest is a validation error about the request as a whole, such as a
+// malformed parameter.
+func BadRequest(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// Invalid is a validation error listing what is wrong with each field.
+func Invalid(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: message, Fields: fields}
+}
+
+// Unprocessable is a well-formed request the API can't act on as sent,
+// listing the fields at fault.
+func Unprocessable(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message, Fields: fields}
+}
+
+// Wrap reports err, a failure of something the API depends on, as an
+// internal error.
+func Wrap(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "Something went wrong; please try again", Err: err}
+}
+
+// From returns err as an *Error, treating errors of any other type as
+// internal.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(err)
+}
+
+// InvalidBody reports a request body that couldn't be decoded, naming the
+// field when a value had the wrong type.
+func InvalidBody(err error) *Error {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return Invalid("Request body has a field of the wrong type", FieldError{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", jsonType(typeErr.Type.Kind().String())),
+		})
+	case errors.Is(err, io.EOF):
+		return BadRequest("invalid_body", "Request body is empty")
+	default:
+		return &Error{Kind: KindValidation, Code: "invalid_body", Message: "Request body is not valid JSON", Err: err}
+	}
+}
+
+// jsonType names a Go kind the way a JSON client would.
+func jsonType(kind string) string {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "slice", "array":
+		return "array"
+	case "map", "struct":
+		return "object"
+	}
+	return kind
+}
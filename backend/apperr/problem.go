@@ -0,0 +1,34 @@
+package apperr
+
+import "net/http"
+
+// ContentType is the media type of problem details.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code and Errors are
+// extension members.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// Problem describes e for the client. instance is the path of the request
+// that failed.
+func (e *Error) Problem(instance string) Problem {
+	status := e.Status()
+	return Problem{
+		// The code already names the problem; there are no pages to link
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
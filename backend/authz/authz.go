@@ -0,0 +1,111 @@
+// Package authz decides who may do what to categories and their questions.
+// Handlers look up the caller's role on the category and ask Decide; the
+// rules for every action live in one table here.
+package authz
+
+import "interview-prep/models"
+
+type Action string
+
+const (
+	ViewQuestions    Action = "view_questions"
+	ReviewQuestion   Action = "review_question"
+	ExportCategory   Action = "export_category"
+	CreateQuestion   Action = "create_question"
+	EditQuestion     Action = "edit_question"
+	DeleteQuestion   Action = "delete_question"
+	RestoreQuestion  Action = "restore_question"
+	CreateTag        Action = "create_tag"
+	ManageTags       Action = "manage_tags"
+	ManageRequests   Action = "manage_requests"
+	ManageMembers    Action = "manage_members"
+	TransferCategory Action = "transfer_category"
+	DeleteCategory   Action = "delete_category"
+)
+
+// rule is the weakest category role allowed to take an action. For
+// actions on a question, others is the role needed when the caller didn't
+// write it.
+type rule struct {
+	min    string
+	others string
+	denied string
+}
+
+var rules = map[Action]rule{
+	ViewQuestions:    {min: models.RoleViewer, denied: "You do not have access to this category"},
+	ReviewQuestion:   {min: models.RoleViewer, denied: "You do not have permission to review questions in this category"},
+	ExportCategory:   {min: models.RoleViewer, denied: "You do not have permission to export this category"},
+	CreateQuestion:   {min: models.RoleContributor, denied: "You do not have permission to add questions to this category"},
+	EditQuestion:     {min: models.RoleContributor, others: models.RoleEditor, denied: "You do not have permission to edit this question"},
+	DeleteQuestion:   {min: models.RoleContributor, others: models.RoleEditor, denied: "You do not have permission to delete this question"},
+	RestoreQuestion:  {min: models.RoleContributor, others: models.RoleEditor, denied: "You do not have permission to restore this question"},
+	CreateTag:        {min: models.RoleContributor, denied: "You do not have permission to add tags to this category"},
+	ManageTags:       {min: models.RoleEditor, denied: "Only owners and editors can rename or delete tags"},
+	ManageRequests:   {min: models.RoleEditor, denied: "Only owners and editors can manage access requests"},
+	ManageMembers:    {min: models.RoleOwner, denied: "Only the owner can manage members"},
+	TransferCategory: {min: models.RoleOwner, denied: "Only the owner can transfer a category"},
+	DeleteCategory:   {min: models.RoleOwner, denied: "You can only delete categories you own"},
+}
+
+// Subject is the authenticated caller.
+type Subject struct {
+	UserID int
+	Admin  bool
+}
+
+// Resource is what an action targets: a category, seen through the
+// subject's role on it, and for question actions the question itself.
+type Resource struct {
+	Role     string
+	Question *models.Question
+}
+
+// Decision is the outcome of a check, mapped by handlers to 200, 403 or 404.
+type Decision int
+
+const (
+	Allow Decision = iota
+	Forbidden
+	// NotFound hides a question from callers with no role on its category,
+	// so probing ids doesn't reveal what exists. Categories themselves are
+	// listed publicly and are never hidden.
+	NotFound
+)
+
+// Decide applies the rule for action. Admins act as the owner of every
+// category.
+func Decide(s Subject, action Action, r Resource) Decision {
+	rl, ok := rules[action]
+	if !ok {
+		return Forbidden
+	}
+
+	role := r.Role
+	if s.Admin {
+		role = models.RoleOwner
+	}
+	if r.Question != nil && role == "" {
+		return NotFound
+	}
+	if !models.RoleAtLeast(role, rl.min) {
+		return Forbidden
+	}
+	if r.Question != nil && rl.others != "" && r.Question.CreatedBy != s.UserID && !models.RoleAtLeast(role, rl.others) {
+		return Forbidden
+	}
+	return Allow
+}
+
+// Can reports whether Decide allows the action.
+func Can(s Subject, action Action, r Resource) bool {
+	return Decide(s, action, r) == Allow
+}
+
+// Message explains a Forbidden decision for action.
+func Message(action Action) string {
+	if rl, ok := rules[action]; ok {
+		return rl.denied
+	}
+	return "Not authorized"
+}
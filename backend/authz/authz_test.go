@@ -0,0 +1,103 @@
+package authz
+
+import (
+	"interview-prep/models"
+	"testing"
+)
+
+func TestDecideCategoryActions(t *testing.T) {
+	const (
+		A = Allow
+		F = Forbidden
+	)
+	roles := []string{"", models.RoleViewer, models.RoleContributor, models.RoleEditor, models.RoleOwner}
+	// Decisions for no role, viewer, contributor, editor and owner
+	tests := []struct {
+		action Action
+		want   [5]Decision
+	}{
+		{ViewQuestions, [5]Decision{F, A, A, A, A}},
+		{ReviewQuestion, [5]Decision{F, A, A, A, A}},
+		{ExportCategory, [5]Decision{F, A, A, A, A}},
+		{CreateQuestion, [5]Decision{F, F, A, A, A}},
+		{EditQuestion, [5]Decision{F, F, A, A, A}},
+		{DeleteQuestion, [5]Decision{F, F, A, A, A}},
+		{RestoreQuestion, [5]Decision{F, F, A, A, A}},
+		{CreateTag, [5]Decision{F, F, A, A, A}},
+		{ManageTags, [5]Decision{F, F, F, A, A}},
+		{ManageRequests, [5]Decision{F, F, F, A, A}},
+		{ManageMembers, [5]Decision{F, F, F, F, A}},
+		{TransferCategory, [5]Decision{F, F, F, F, A}},
+		{DeleteCategory, [5]Decision{F, F, F, F, A}},
+		{Action("unknown"), [5]Decision{F, F, F, F, F}},
+	}
+	for _, tt := range tests {
+		for i, role := range roles {
+			if got := Decide(Subject{UserID: 1}, tt.action, Resource{Role: role}); got != tt.want[i] {
+				t.Errorf("Decide(%s, role %q) = %v, want %v", tt.action, role, got, tt.want[i])
+			}
+		}
+		// Admins act as the owner whatever their role
+		want := tt.want[4]
+		for _, role := range roles {
+			if got := Decide(Subject{UserID: 1, Admin: true}, tt.action, Resource{Role: role}); got != want {
+				t.Errorf("Decide(%s, admin with role %q) = %v, want %v", tt.action, role, got, want)
+			}
+		}
+	}
+}
+
+func TestDecideQuestionActions(t *testing.T) {
+	const me, someoneElse = 1, 2
+	mine := &models.Question{ID: 10, CreatedBy: me}
+	theirs := &models.Question{ID: 11, CreatedBy: someoneElse}
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		role     string
+		question *models.Question
+		want     Decision
+	}{
+		{"no role hides the question", Subject{UserID: me}, ViewQuestions, "", theirs, NotFound},
+		{"no role hides even my own question", Subject{UserID: me}, EditQuestion, "", mine, NotFound},
+		{"viewer sees", Subject{UserID: me}, ViewQuestions, models.RoleViewer, theirs, Allow},
+		{"viewer reviews", Subject{UserID: me}, ReviewQuestion, models.RoleViewer, theirs, Allow},
+		{"viewer can't edit", Subject{UserID: me}, EditQuestion, models.RoleViewer, mine, Forbidden},
+		{"contributor edits own", Subject{UserID: me}, EditQuestion, models.RoleContributor, mine, Allow},
+		{"contributor can't edit others'", Subject{UserID: me}, EditQuestion, models.RoleContributor, theirs, Forbidden},
+		{"contributor deletes own", Subject{UserID: me}, DeleteQuestion, models.RoleContributor, mine, Allow},
+		{"contributor can't delete others'", Subject{UserID: me}, DeleteQuestion, models.RoleContributor, theirs, Forbidden},
+		{"contributor can't restore others'", Subject{UserID: me}, RestoreQuestion, models.RoleContributor, theirs, Forbidden},
+		{"editor edits others'", Subject{UserID: me}, EditQuestion, models.RoleEditor, theirs, Allow},
+		{"editor deletes others'", Subject{UserID: me}, DeleteQuestion, models.RoleEditor, theirs, Allow},
+		{"editor restores others'", Subject{UserID: me}, RestoreQuestion, models.RoleEditor, theirs, Allow},
+		{"owner edits others'", Subject{UserID: me}, EditQuestion, models.RoleOwner, theirs, Allow},
+		{"admin sees without a role", Subject{UserID: me, Admin: true}, ViewQuestions, "", theirs, Allow},
+		{"admin edits without a role", Subject{UserID: me, Admin: true}, EditQuestion, "", theirs, Allow},
+		{"admin deletes without a role", Subject{UserID: me, Admin: true}, DeleteQuestion, "", theirs, Allow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decide(tt.subject, tt.action, Resource{Role: tt.role, Question: tt.question})
+			if got != tt.want {
+				t.Errorf("Decide = %v, want %v", got, tt.want)
+			}
+			if can := Can(tt.subject, tt.action, Resource{Role: tt.role, Question: tt.question}); can != (tt.want == Allow) {
+				t.Errorf("Can = %v, want %v", can, tt.want == Allow)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	for action, rl := range rules {
+		if rl.denied == "" || Message(action) != rl.denied {
+			t.Errorf("Message(%s) = %q", action, Message(action))
+		}
+	}
+	if Message(Action("unknown")) == "" {
+		t.Error("unknown actions have no message")
+	}
+}
@@ -0,0 +1,434 @@
+// Package config loads the server's settings. Each setting starts at its
+// default and is overridden, in turn, by the config file, the environment
+// and the command line; the result is validated once, at startup, and
+// passed to whatever needs it.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Modes the server runs in. Production refuses insecure defaults.
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+const (
+	// DefaultJWTSecret signs tokens in development when no secret is set.
+	// It is public, so production refuses it.
+	DefaultJWTSecret = "your_secret_key"
+	// MinJWTSecretLength is the shortest secret production accepts.
+	MinJWTSecretLength = 32
+
+	// DevDatabaseURL is used in development when no database is set.
+	DevDatabaseURL = "host=localhost port=5432 user=postgres dbname=interview_prep sslmode=disable"
+)
+
+type Config struct {
+	// Env is Development or Production.
+	Env  string `yaml:"env" toml:"env"`
+	Port int    `yaml:"port" toml:"port"`
+	// Timeouts of the HTTP server. WriteTimeout bounds a whole response,
+	// including grading an answer, so it must outlast the grader.
+	ReadTimeout  Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownDelay is how long the server keeps serving, with readiness
+	// failing, after it is told to stop, so load balancers can stop sending
+	// it traffic. ShutdownTimeout is then how long requests in flight get
+	// to finish.
+	ShutdownDelay   Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// DatabaseURL falls back to DevDatabaseURL in development only.
+	DatabaseURL string `yaml:"database_url" toml:"database_url"`
+	JWTSecret   string `yaml:"jwt_secret" toml:"jwt_secret"`
+	// AppURL is the frontend that links in emails point to.
+	AppURL string `yaml:"app_url" toml:"app_url"`
+	// AllowedOrigins may call the API from a browser, as may the local
+	// frontend dev servers and any origin ending in one of
+	// AllowedOriginSuffixes.
+	AllowedOrigins        []string `yaml:"allowed_origins" toml:"allowed_origins"`
+	AllowedOriginSuffixes []string `yaml:"allowed_origin_suffixes" toml:"allowed_origin_suffixes"`
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For
+	// headers are believed.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+
+	Log    Log    `yaml:"log" toml:"log"`
+	Mail   Mail   `yaml:"mail" toml:"mail"`
+	OIDC   OIDC   `yaml:"oidc" toml:"oidc"`
+	Grader Grader `yaml:"grader" toml:"grader"`
+}
+
+// Log sets what the server logs and how: Level is debug, info, warn or
+// error, and Format json or text.
+type Log struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// Mail goes through SMTP when SMTPHost is set, is otherwise saved under
+// Dir, and is logged when neither is.
+type Mail struct {
+	From         string `yaml:"from" toml:"from"`
+	SMTPHost     string `yaml:"smtp_host" toml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port" toml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username" toml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password" toml:"smtp_password"`
+	Dir          string `yaml:"dir" toml:"dir"`
+}
+
+// OIDC turns single sign-on on when Issuer is set. RedirectURL defaults to
+// the frontend's callback page.
+type OIDC struct {
+	Name         string   `yaml:"name" toml:"name"`
+	Issuer       string   `yaml:"issuer" toml:"issuer"`
+	ClientID     string   `yaml:"client_id" toml:"client_id"`
+	ClientSecret string   `yaml:"client_secret" toml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url" toml:"redirect_url"`
+	Scopes       []string `yaml:"scopes" toml:"scopes"`
+}
+
+// Grader grades answers with the model at URL when it is set.
+type Grader struct {
+	URL     string   `yaml:"url" toml:"url"`
+	Model   string   `yaml:"model" toml:"model"`
+	APIKey  string   `yaml:"api_key" toml:"api_key"`
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// Duration is a time.Duration written as in "30s" in config files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Default is the configuration before any file, variable or flag is read.
+func Default() *Config {
+	return &Config{
+		Env:                   Development,
+		Port:                  8081,
+		ReadTimeout:           Duration(15 * time.Second),
+		WriteTimeout:          Duration(60 * time.Second),
+		IdleTimeout:           Duration(2 * time.Minute),
+		ShutdownTimeout:       Duration(20 * time.Second),
+		AppURL:                "http://localhost:5173",
+		AllowedOriginSuffixes: []string{".vercel.app"},
+		Log:                   Log{Level: "info", Format: "json"},
+		Mail: Mail{
+			From:     "Prepterview <no-reply@localhost>",
+			SMTPPort: 587,
+		},
+		OIDC:   OIDC{Name: "SSO"},
+		Grader: Grader{Timeout: Duration(30 * time.Second)},
+	}
+}
+
+// setting binds a field to its environment variable and, for settings
+// that aren't secret, a command-line flag. Secrets have no flag, since
+// command lines are visible to other users of the machine.
+type setting struct {
+	env, flag, usage string
+	value            any
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"APP_ENV", "env", "development or production", &c.Env},
+		{"PORT", "port", "port to listen on", &c.Port},
+		{"HTTP_READ_TIMEOUT", "read-timeout", "longest time to read a request", &c.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", "write-timeout", "longest time to handle a request and write the response", &c.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", "idle-timeout", "how long an idle keep-alive connection stays open", &c.IdleTimeout},
+		{"SHUTDOWN_DELAY", "shutdown-delay", "how long to keep serving after a stop signal before shutting down", &c.ShutdownDelay},
+		{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long requests in flight get to finish on shutdown", &c.ShutdownTimeout},
+		{"DATABASE_URL", "database-url", "PostgreSQL connection string", &c.DatabaseURL},
+		{"JWT_SECRET", "", "", &c.JWTSecret},
+		{"APP_URL", "app-url", "frontend URL that links in emails point to", &c.AppURL},
+		{"ALLOWED_ORIGIN", "allowed-origins", "comma-separated origins allowed by CORS", &c.AllowedOrigins},
+		{"ALLOWED_ORIGIN_SUFFIXES", "allowed-origin-suffixes", "comma-separated origin suffixes allowed by CORS", &c.AllowedOriginSuffixes},
+		{"TRUSTED_PROXIES", "trusted-proxies", "comma-separated proxies whose X-Forwarded-For is believed", &c.TrustedProxies},
+
+		{"LOG_LEVEL", "log-level", "least severe level logged: debug, info, warn or error", &c.Log.Level},
+		{"LOG_FORMAT", "log-format", "log as json or text", &c.Log.Format},
+
+		{"MAIL_FROM", "mail-from", "sender of outgoing mail", &c.Mail.From},
+		{"SMTP_HOST", "smtp-host", "SMTP server to send mail through", &c.Mail.SMTPHost},
+		{"SMTP_PORT", "smtp-port", "port of the SMTP server", &c.Mail.SMTPPort},
+		{"SMTP_USERNAME", "smtp-username", "SMTP user name", &c.Mail.SMTPUsername},
+		{"SMTP_PASSWORD", "", "", &c.Mail.SMTPPassword},
+		{"MAIL_DIR", "mail-dir", "directory to save mail in when there is no SMTP server", &c.Mail.Dir},
+
+		{"OIDC_NAME", "oidc-name", "name of the identity provider shown on the sign-in button", &c.OIDC.Name},
+		{"OIDC_ISSUER", "oidc-issuer", "OpenID Connect issuer to offer sign-in through", &c.OIDC.Issuer},
+		{"OIDC_CLIENT_ID", "oidc-client-id", "client ID registered with the issuer", &c.OIDC.ClientID},
+		{"OIDC_CLIENT_SECRET", "", "", &c.OIDC.ClientSecret},
+		{"OIDC_REDIRECT_URL", "oidc-redirect-url", "redirect URL registered with the issuer", &c.OIDC.RedirectURL},
+		{"OIDC_SCOPES", "oidc-scopes", "space-separated scopes to request", &c.OIDC.Scopes},
+
+		{"GRADER_URL", "grader-url", "chat completions endpoint to grade answers with", &c.Grader.URL},
+		{"GRADER_MODEL", "grader-model", "model to grade answers with", &c.Grader.Model},
+		{"GRADER_API_KEY", "", "", &c.Grader.APIKey},
+		{"GRADER_TIMEOUT", "grader-timeout", "how long to wait for the grader", &c.Grader.Timeout},
+	}
+}
+
+// Load reads the configuration for a server started with args, the
+// command line without the program name, and returns the arguments left
+// after the flags. The config file is named by -config or CONFIG_FILE.
+func Load(args []string) (*Config, []string, error) {
+	c := Default()
+	settings := c.settings()
+
+	fs := flag.NewFlagSet("interview-prep", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML file to read settings from")
+	flags := map[string]string{}
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		fs.Func(s.flag, s.usage, func(raw string) error {
+			flags[s.flag] = raw
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, nil, err
+	}
+
+	if *path != "" {
+		if err := c.readFile(*path); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, s := range settings {
+		raw, ok := os.LookupEnv(s.env)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := set(s.value, raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %w", s.env, err)
+		}
+	}
+	for _, s := range settings {
+		raw, ok := flags[s.flag]
+		if !ok || s.flag == "" {
+			continue
+		}
+		if err := set(s.value, raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid -%s: %w", s.flag, err)
+		}
+	}
+
+	c.fillIn()
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return c, fs.Args(), nil
+}
+
+// readFile overrides c with the settings in the file at path, which must
+// be YAML or TOML by its extension. Unknown keys are refused, so a typo
+// isn't silently ignored.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(f, yaml.DisallowUnknownField()).Decode(c)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		dec := toml.NewDecoder(f)
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	default:
+		return fmt.Errorf("config file %s: unknown format %q; use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// set parses raw into the field value points to. Lists are separated by
+// commas or spaces.
+func set(value any, raw string) error {
+	switch v := value.(type) {
+	case *string:
+		*v = raw
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		*v = n
+	case *Duration:
+		return v.UnmarshalText([]byte(raw))
+	case *[]string:
+		*v = splitList(raw)
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", value))
+	}
+	return nil
+}
+
+func splitList(raw string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// devOrigins are the local frontend dev servers, which are always allowed
+// on top of AllowedOrigins.
+var devOrigins = []string{"http://localhost:5173", "http://localhost:3000"}
+
+// fillIn derives the defaults that depend on other settings.
+func (c *Config) fillIn() {
+	for _, origin := range devOrigins {
+		if !slices.Contains(c.AllowedOrigins, origin) {
+			c.AllowedOrigins = append(c.AllowedOrigins, origin)
+		}
+	}
+	c.AppURL = strings.TrimSuffix(c.AppURL, "/")
+	if c.OIDC.Issuer != "" && c.OIDC.RedirectURL == "" {
+		c.OIDC.RedirectURL = c.AppURL + "/oidc/callback"
+	}
+	if c.Env == Development {
+		if c.DatabaseURL == "" {
+			c.DatabaseURL = DevDatabaseURL
+		}
+		if c.JWTSecret == "" {
+			c.JWTSecret = DefaultJWTSecret
+		}
+	}
+}
+
+func (c *Config) Production() bool {
+	return c.Env == Production
+}
+
+// Validate reports every setting that is missing or malformed, and, in
+// production, every secret left at its default.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Env != Development && c.Env != Production {
+		fail("env must be %s or %s, not %q", Development, Production, c.Env)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		fail("port must be between 1 and 65535")
+	}
+	for _, timeout := range []struct {
+		name  string
+		value Duration
+	}{
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+	} {
+		if timeout.value <= 0 {
+			fail("%s must be positive", timeout.name)
+		}
+	}
+	if c.ShutdownDelay < 0 {
+		fail("shutdown_delay cannot be negative")
+	}
+	if c.DatabaseURL == "" {
+		fail("database_url is required")
+	}
+	if err := checkURL(c.AppURL); err != nil {
+		fail("app_url: %v", err)
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.Log.Level)) {
+		fail("log.level must be debug, info, warn or error, not %q", c.Log.Level)
+	}
+	if !slices.Contains([]string{"json", "text"}, c.Log.Format) {
+		fail("log.format must be json or text, not %q", c.Log.Format)
+	}
+	if c.Mail.SMTPHost != "" && (c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535) {
+		fail("mail.smtp_port must be between 1 and 65535")
+	}
+	if c.OIDC.Issuer != "" {
+		if err := checkURL(c.OIDC.Issuer); err != nil {
+			fail("oidc.issuer: %v", err)
+		}
+		if c.OIDC.ClientID == "" {
+			fail("oidc.client_id is required with oidc.issuer")
+		}
+		if err := checkURL(c.OIDC.RedirectURL); err != nil {
+			fail("oidc.redirect_url: %v", err)
+		}
+	}
+	if c.Grader.URL != "" {
+		if err := checkURL(c.Grader.URL); err != nil {
+			fail("grader.url: %v", err)
+		}
+		if c.Grader.Timeout <= 0 {
+			fail("grader.timeout must be positive")
+		} else if c.Grader.Timeout >= c.WriteTimeout {
+			fail("write_timeout must be longer than grader.timeout")
+		}
+	}
+
+	switch {
+	case c.JWTSecret == "":
+		fail("jwt_secret is required")
+	case c.Production() && c.JWTSecret == DefaultJWTSecret:
+		fail("jwt_secret must not be the default in production")
+	case c.Production() && len(c.JWTSecret) < MinJWTSecretLength:
+		fail("jwt_secret must be at least %d characters in production", MinJWTSecretLength)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// checkURL accepts absolute http and https URLs.
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", raw)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"interview-prep/apperr"
+	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/logging"
+	"interview-prep/mail"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mailTimeout bounds how long sending one email may take.
+const mailTimeout = 30 * time.Second
+
+// deliver sends m in the background and logs failures with the request of
+// ctx. Responses never wait for mail, so they can't reveal whether an
+// address has an account.
+func (uc *UserController) deliver(ctx context.Context, m mail.Message) {
+	logger := logging.FromContext(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(logging.NewContext(context.Background(), logger), mailTimeout)
+		defer cancel()
+		if err := uc.Mailer.Send(ctx, m); err != nil {
+			logger.Error("sending mail failed", "subject", m.Subject, "to", m.To, "error", err)
+		}
+	}()
+}
+
+// link is the frontend page at path with token in its query string.
+func (uc *UserController) link(path, token string) string {
+	return uc.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendVerification mails user a fresh link to verify their address.
+func (uc *UserController) sendVerification(ctx context.Context, user *models.User) error {
+	token, hash := helpers.GenerateAccountToken()
+	if err := uc.Accounts.CreateAccountToken(ctx, user.ID, models.TokenVerifyEmail, hash, time.Now().Add(helpers.VerifyEmailTokenTTL)); err != nil {
+		return err
+	}
+	uc.deliver(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address to start using Prepterview:\n\n%s\n\n"+
+			"The link expires in %d hours. If you didn't sign up, you can ignore this email.\n",
+			user.FirstName, uc.link("/verify-email", token), int(helpers.VerifyEmailTokenTTL.Hours())),
+	})
+	return nil
+}
+
+// sendPasswordReset mails user a fresh password reset link, ending with
+// note.
+func (uc *UserController) sendPasswordReset(ctx context.Context, user *models.User, note string) error {
+	token, hash := helpers.GenerateAccountToken()
+	if err := uc.Accounts.CreateAccountToken(ctx, user.ID, models.TokenResetPassword, hash, time.Now().Add(helpers.ResetPasswordTokenTTL)); err != nil {
+		return err
+	}
+	uc.deliver(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nChoose a new Prepterview password here:\n\n%s\n\n"+
+			"The link expires in %d minutes and works once. %s\n",
+			user.FirstName, uc.link("/reset-password", token), int(helpers.ResetPasswordTokenTTL.Minutes()), note),
+	})
+	return nil
+}
+
+// VerifyEmail consumes a verification token and signs the user in.
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := uc.Accounts.VerifyEmail(c.Request.Context(), helpers.HashToken(req.Token))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.BadRequest("invalid_token", "Invalid or expired verification link"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	if !uc.canSignIn(c, user) {
+		return
+	}
+
+	resp, err := uc.startSession(c.Request.Context(), user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	resp["user"] = user
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResendVerification mails a new verification link to an unverified
+// account. The response is the same whether or not one exists.
+func (uc *UserController) ResendVerification(c *gin.Context) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+
+	user, err := uc.Users.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.Error(err)
+		return
+	}
+	if err == nil && user.EmailVerifiedAt == nil {
+		if err := uc.sendVerification(c.Request.Context(), user); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If that address has an unverified account, a new link is on its way"})
+}
+
+// ForgotPassword mails a password reset link. The response is the same
+// whether or not the address has an account.
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := uc.Users.GetUserByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.Error(err)
+		return
+	}
+	if err == nil {
+		if err := uc.sendPasswordReset(ctx, user, "If you didn't ask for it, you can ignore this email."); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If that address has an account, a reset link is on its way"})
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out everywhere.
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	if len(req.Password) < 6 {
+		c.Error(apperr.BadRequest("password_too_short", "Password must be at least 6 characters long"))
+		return
+	}
+
+	errInvalidToken := apperr.BadRequest("invalid_token", "Invalid or expired reset link")
+	// Hashing is slow on purpose, so a bad link is turned away before it
+	tokenHash := helpers.HashToken(req.Token)
+	err := uc.Accounts.CheckAccountToken(c.Request.Context(), models.TokenResetPassword, tokenHash)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errInvalidToken)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	hashedPassword, err := helpers.HashPassword(req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The token may have been used meanwhile; consuming it decides
+	_, err = uc.Accounts.ResetPassword(c.Request.Context(), tokenHash, hashedPassword)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errInvalidToken)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated; sign in with your new password"})
+}
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/handlers"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin answers 403 unless the caller is an admin.
+func requireAdmin(c *gin.Context) bool {
+	if c.GetString("role") != models.RoleAdmin {
+		c.Error(apperr.Forbidden("access_denied", "Access denied"))
+		return false
+	}
+	return true
+}
+
+// adminTarget checks the caller is an admin and parses the user id the
+// request acts on.
+func adminTarget(c *gin.Context) (int, bool) {
+	if !requireAdmin(c) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid_id", "Invalid ID"))
+		return 0, false
+	}
+	return id, true
+}
+
+// adminError answers for the errors the admin store returns.
+func adminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.Error(errUserNotFound)
+	case errors.Is(err, store.ErrLastAdmin):
+		c.Error(apperr.Conflict("last_admin", "Cannot remove the only admin"))
+	default:
+		c.Error(err)
+	}
+}
+
+// SetUserRole promotes a user to admin or demotes one. The user is signed
+// out so the new role applies at once.
+func (uc *UserController) SetUserRole(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	if req.Role != models.RoleAdmin && req.Role != models.RoleUser {
+		c.Error(apperr.BadRequest("invalid_role", "Role must be either ADMIN or USER"))
+		return
+	}
+
+	if err := uc.Admin.SetUserRole(c.Request.Context(), c.GetInt("user_id"), id, req.Role); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated"})
+}
+
+// LockUser stops a user from signing in and ends their sessions.
+func (uc *UserController) LockUser(c *gin.Context) {
+	uc.setLocked(c, true)
+}
+
+func (uc *UserController) UnlockUser(c *gin.Context) {
+	uc.setLocked(c, false)
+}
+
+func (uc *UserController) setLocked(c *gin.Context, locked bool) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	if locked && id == c.GetInt("user_id") {
+		c.Error(apperr.BadRequest("self_action", "You cannot lock your own account"))
+		return
+	}
+
+	if err := uc.Admin.SetUserLocked(c.Request.Context(), c.GetInt("user_id"), id, locked); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	if locked {
+		c.JSON(http.StatusOK, gin.H{"message": "Account locked"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+	}
+}
+
+// ForcePasswordReset signs a user out and blocks sign-in until they set a
+// new password through the reset link mailed to them.
+func (uc *UserController) ForcePasswordReset(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	user, err := uc.Users.GetUser(ctx, id)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	if err := uc.Admin.RequirePasswordReset(ctx, c.GetInt("user_id"), id); err != nil {
+		adminError(c, err)
+		return
+	}
+	if err := uc.sendPasswordReset(ctx, user, "An administrator asked you to choose a new password before signing in again."); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset required; a reset link was mailed to the user"})
+}
+
+var auditList = handlers.ListSpec{Sorts: []string{models.SortCreatedAt}, DefaultDesc: true, Filters: []string{handlers.FilterUser}}
+
+// GetAuditLog lists administrative changes to accounts, newest first, or
+// those about one user with user_id.
+func (uc *UserController) GetAuditLog(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	q, ok := handlers.ParseListQuery(c, auditList)
+	if !ok {
+		return
+	}
+
+	entries, err := uc.Admin.ListAuditLog(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, handlers.NewPage(entries, q, func(e models.AuditEntry) int { return e.ID }))
+}
+
+// profileRequest is the editable part of a user's profile.
+type profileRequest struct {
+	FirstName string `json:"first_name" validate:"required,min=2,max=100"`
+	LastName  string `json:"last_name" validate:"required,min=2,max=100"`
+	Email     string `json:"email" validate:"required,email"`
+	Phone     string `json:"phone" validate:"required"`
+}
+
+// bindProfile reads and validates a profileRequest, answering 400 when it
+// can't be used.
+func bindProfile(c *gin.Context) (profileRequest, bool) {
+	var req profileRequest
+	if !handlers.BindJSON(c, &req) {
+		return req, false
+	}
+	return req, true
+}
+
+// UpdateUser edits a user's profile. Their email stays verified; admins are
+// trusted to enter addresses correctly.
+func (uc *UserController) UpdateUser(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	req, ok := bindProfile(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	user, err := uc.Users.GetUser(ctx, id)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	user.FirstName, user.LastName, user.Email, user.Phone = req.FirstName, req.LastName, req.Email, req.Phone
+
+	err = uc.Admin.EditUser(ctx, c.GetInt("user_id"), user)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(errEmailOrPhoneTaken)
+		return
+	} else if err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser deletes an account. categories=delete deletes the categories
+// it owns along with their questions; categories=reassign&to=ID hands them
+// to another user.
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	if id == c.GetInt("user_id") {
+		c.Error(apperr.BadRequest("self_action", "You cannot delete your own account"))
+		return
+	}
+	ctx := c.Request.Context()
+
+	reassignTo := 0
+	switch c.Query("categories") {
+	case "delete":
+	case "reassign":
+		var err error
+		if reassignTo, err = strconv.Atoi(c.Query("to")); err != nil {
+			c.Error(apperr.BadRequest("invalid_reassign_target", "to must be the id of the user to reassign categories to"))
+			return
+		}
+		if reassignTo == id {
+			c.Error(apperr.BadRequest("invalid_reassign_target", "Cannot reassign categories to the user being deleted"))
+			return
+		}
+		if _, err := uc.Users.GetUser(ctx, reassignTo); errors.Is(err, store.ErrNotFound) {
+			c.Error(apperr.BadRequest("invalid_reassign_target", "No user to reassign categories to"))
+			return
+		} else if err != nil {
+			adminError(c, err)
+			return
+		}
+	default:
+		c.Error(apperr.BadRequest("invalid_categories_mode", "categories must be delete or reassign"))
+		return
+	}
+
+	if err := uc.Admin.DeleteUser(ctx, c.GetInt("user_id"), id, reassignTo); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+// SuspendUser suspends an account until the given time, or until lifted.
+// The user is signed out and can't sign in again until it ends.
+func (uc *UserController) SuspendUser(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	if id == c.GetInt("user_id") {
+		c.Error(apperr.BadRequest("self_action", "You cannot suspend your own account"))
+		return
+	}
+	var req struct {
+		Until  *time.Time `json:"until"`
+		Reason string     `json:"reason"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		c.Error(apperr.BadRequest("invalid_until", "until must be in the future"))
+		return
+	}
+
+	if err := uc.Admin.SuspendUser(c.Request.Context(), c.GetInt("user_id"), id, req.Until, req.Reason); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account suspended"})
+}
+
+func (uc *UserController) UnsuspendUser(c *gin.Context) {
+	id, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := uc.Admin.UnsuspendUser(c.Request.Context(), c.GetInt("user_id"), id); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suspension lifted"})
+}
+
+// GetUserSummary shows a user with the categories they own and their
+// access to others'. Users may see their own.
+func (uc *UserController) GetUserSummary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid_id", "Invalid ID"))
+		return
+	}
+	if id != c.GetInt("user_id") && !requireAdmin(c) {
+		return
+	}
+
+	summary, err := uc.Users.UserSummary(c.Request.Context(), id)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/logging"
+	"interview-prep/models"
+	"interview-prep/oidc"
+	"interview-prep/store"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcLoginTTL is how long a user has to sign in at the provider.
+const oidcLoginTTL = 10 * time.Minute
+
+// requireOIDC answers 404 when single sign-on is off.
+func (uc *UserController) requireOIDC(c *gin.Context) bool {
+	if uc.OIDC == nil {
+		c.Error(apperr.NotFound("oidc_disabled", "Single sign-on is not configured"))
+		return false
+	}
+	return true
+}
+
+// GetOIDCConfig tells the frontend whether to offer single sign-on.
+func (uc *UserController) GetOIDCConfig(c *gin.Context) {
+	if uc.OIDC == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "name": uc.OIDC.Name})
+}
+
+// StartOIDCLogin returns the provider URL to send the user to. The
+// provider redirects back to the frontend, which hands the code and state
+// to OIDCCallback.
+func (uc *UserController) StartOIDCLogin(c *gin.Context) {
+	if !uc.requireOIDC(c) {
+		return
+	}
+	ctx := c.Request.Context()
+
+	state := oidc.NewState()
+	login := models.OIDCLogin{
+		StateHash:    helpers.HashToken(state),
+		Nonce:        oidc.NewState(),
+		CodeVerifier: oidc.NewVerifier(),
+		ExpiresAt:    time.Now().Add(oidcLoginTTL),
+	}
+	authURL, err := uc.OIDC.AuthCodeURL(ctx, state, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		c.Error(apperr.Unavailable("oidc_unavailable", "The identity provider is unavailable", err))
+		return
+	}
+	if err := uc.Identities.SaveOIDCLogin(ctx, &login); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// OIDCCallback finishes a sign-in at the provider and starts a session for
+// the user it names, linking or creating one the first time.
+func (uc *UserController) OIDCCallback(c *gin.Context) {
+	if !uc.requireOIDC(c) {
+		return
+	}
+	var req struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	ctx := c.Request.Context()
+
+	login, err := uc.Identities.TakeOIDCLogin(ctx, helpers.HashToken(req.State))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.BadRequest("oidc_login_expired", "Sign-in expired or was already used; please try again"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	claims, err := uc.OIDC.Exchange(ctx, req.Code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		logging.FromContext(ctx).Warn("OIDC sign-in failed", "error", err)
+		c.Error(apperr.Unauthorized("oidc_exchange_failed", "The identity provider did not confirm the sign-in"))
+		return
+	}
+	if claims.Email == "" {
+		c.Error(apperr.BadRequest("oidc_email_missing", "The identity provider did not share an email address"))
+		return
+	}
+
+	first, last := identityName(claims)
+	user, created, err := uc.Identities.SignInWithIdentity(ctx, models.ExternalIdentity{
+		Issuer:        uc.OIDC.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		FirstName:     first,
+		LastName:      last,
+	})
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(apperr.Conflict("identity_not_linked", "An account with this email already exists; sign in with your password"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if created && user.EmailVerifiedAt == nil {
+		if err := uc.sendVerification(ctx, user); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if !uc.canSignIn(c, user) {
+		return
+	}
+
+	resp, err := uc.startSession(ctx, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	resp["user"] = user
+	resp["created"] = created
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// identityName picks a first and last name from what the provider shared,
+// falling back to the email's local part.
+func identityName(claims *oidc.Claims) (string, string) {
+	if claims.GivenName != "" || claims.FamilyName != "" {
+		return claims.GivenName, claims.FamilyName
+	}
+	if first, last, ok := strings.Cut(strings.TrimSpace(claims.Name), " "); ok {
+		return first, strings.TrimSpace(last)
+	} else if first != "" {
+		return first, ""
+	}
+	local, _, _ := strings.Cut(claims.Email, "@")
+	return local, ""
+}
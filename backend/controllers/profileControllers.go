@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser loads the caller with their password hash.
+func (uc *UserController) currentUser(c *gin.Context) (*models.User, bool) {
+	ctx := c.Request.Context()
+	user, err := uc.Users.GetUser(ctx, c.GetInt("user_id"))
+	if err == nil {
+		// Only the lookup by email returns the hash
+		user, err = uc.Users.GetUserByEmail(ctx, user.Email)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errUserNotFound)
+		return nil, false
+	} else if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	return user, true
+}
+
+func (uc *UserController) GetMe(c *gin.Context) {
+	user, ok := uc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateMe edits the caller's own profile. Changing the email takes the
+// current password and a new verification, which is mailed to the new
+// address; until then the account is signed out everywhere and can't sign
+// in again.
+func (uc *UserController) UpdateMe(c *gin.Context) {
+	var req struct {
+		profileRequest
+		CurrentPassword string `json:"current_password"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	user, ok := uc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	emailChanged := req.Email != user.Email
+	if emailChanged {
+		if err := helpers.VerifyPassword(user.Password, req.CurrentPassword); err != nil {
+			c.Error(apperr.Unauthorized("incorrect_password", "current_password is incorrect"))
+			return
+		}
+		user.EmailVerifiedAt = nil
+	}
+	user.FirstName, user.LastName, user.Email, user.Phone = req.FirstName, req.LastName, req.Email, req.Phone
+
+	ctx := c.Request.Context()
+	err := uc.Users.UpdateUser(ctx, user)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(errEmailOrPhoneTaken)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !emailChanged {
+		c.JSON(http.StatusOK, gin.H{"message": "Profile updated", "user": user})
+		return
+	}
+	if err := uc.sendVerification(ctx, user); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated; check your email for a link to verify your new address", "user": user})
+}
+
+// ChangeMyPassword sets a new password given the current one and signs out
+// every other session.
+func (uc *UserController) ChangeMyPassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required,min=6"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	user, ok := uc.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := helpers.VerifyPassword(user.Password, req.CurrentPassword); err != nil {
+		c.Error(apperr.Unauthorized("incorrect_password", "current_password is incorrect"))
+		return
+	}
+
+	hashedPassword, err := helpers.HashPassword(req.NewPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := uc.Users.ChangePassword(c.Request.Context(), user.ID, hashedPassword, c.GetString("session_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed; other sessions were signed out"})
+}
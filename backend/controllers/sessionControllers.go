@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/config"
+	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startSession opens a session for user and returns the access and refresh
+// tokens to hand back to the client.
+func (uc *UserController) startSession(ctx context.Context, user *models.User) (gin.H, error) {
+	refreshToken, refreshHash := helpers.GenerateRefreshToken()
+	session := models.Session{
+		ID:        config.GenerateRandomKey(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(helpers.RefreshTokenTTL),
+	}
+	if err := uc.Sessions.CreateSession(ctx, &session, refreshHash); err != nil {
+		return nil, err
+	}
+
+	token, err := uc.Tokens.GenerateToken(user.Email, user.ID, user.Role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(helpers.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (uc *UserController) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !handlers.BindJSON(c, &req) {
+		return
+	}
+	if req.RefreshToken == "" {
+		c.Error(apperr.BadRequest("refresh_token_required", "refresh_token is required"))
+		return
+	}
+
+	refreshToken, refreshHash := helpers.GenerateRefreshToken()
+	session, err := uc.Sessions.RotateRefreshToken(
+		c.Request.Context(),
+		helpers.HashToken(req.RefreshToken),
+		refreshHash,
+		time.Now().Add(helpers.RefreshTokenTTL),
+	)
+	switch {
+	case errors.Is(err, store.ErrRefreshTokenReused):
+		c.Error(apperr.Unauthorized("refresh_token_reused", "Refresh token was already used; session revoked"))
+		return
+	case errors.Is(err, store.ErrSuspended):
+		c.Error(apperr.Forbidden("account_suspended", "This account is suspended"))
+		return
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrSessionInactive):
+		c.Error(apperr.Unauthorized("invalid_refresh_token", "Invalid or expired refresh token"))
+		return
+	case err != nil:
+		c.Error(err)
+		return
+	}
+
+	user, err := uc.Users.GetUser(c.Request.Context(), session.UserID)
+	if err != nil {
+		c.Error(apperr.Unauthorized("invalid_refresh_token", "Invalid or expired refresh token"))
+		return
+	}
+
+	token, err := uc.Tokens.GenerateToken(user.Email, user.ID, user.Role, session.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(helpers.AccessTokenTTL.Seconds()),
+	})
+}
+
+// Logout revokes the session the caller's access token belongs to.
+func (uc *UserController) Logout(c *gin.Context) {
+	err := uc.Sessions.RevokeSession(c.Request.Context(), c.GetString("session_id"))
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAll revokes every session of the caller, signing out all devices.
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	if err := uc.Sessions.RevokeUserSessions(c.Request.Context(), c.GetInt("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
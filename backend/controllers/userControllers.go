@@ -1,98 +1,93 @@
 package controllers
 
 import (
-	"database/sql"
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/handlers"
 	"interview-prep/helpers"
+	"interview-prep/mail"
 	"interview-prep/models"
+	"interview-prep/oidc"
+	"interview-prep/store"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type UserController struct {
-	DB *sql.DB
+	Users      store.UserStore
+	Admin      store.AdminStore
+	Accounts   store.AccountStore
+	Identities store.IdentityStore
+	Sessions   store.SessionStore
+	Mailer     mail.Mailer
+	Tokens     *helpers.Tokens
+	// AppURL is the frontend the links in emails point to.
+	AppURL string
+	// OIDC is the identity provider users may sign in with; nil turns
+	// single sign-on off.
+	OIDC *oidc.Provider
 }
 
-var validate = validator.New()
+var (
+	errEmailOrPhoneTaken = apperr.Conflict("email_or_phone_taken", "Email or phone already exists")
+	errUserNotFound      = apperr.NotFound("user_not_found", "User not found")
+)
 
 func (uc *UserController) Signup(c *gin.Context) {
-	var user models.User
-
-	if err := c.BindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate struct
-	if err := validate.Struct(user); err != nil {
-		// Provide user-friendly error messages
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			for _, fieldError := range validationErrors {
-				switch fieldError.Field() {
-				case "FirstName":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "First name must be at least 2 characters long"})
-					return
-				case "LastName":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Last name must be at least 2 characters long"})
-					return
-				case "Email":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide a valid email address"})
-					return
-				case "Password":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters long"})
-					return
-				case "Phone":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number is required"})
-					return
-				case "Role":
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be either ADMIN or USER"})
-					return
-				}
-			}
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed"})
+	var req models.SignupRequest
+
+	if !handlers.BindJSON(c, &req) {
 		return
 	}
+	user := req.User()
 
 	// Check if user exists
-	var count int
-	err := uc.DB.QueryRow("SELECT COUNT(*) FROM users WHERE email = $1 OR phone = $2", user.Email, user.Phone).Scan(&count)
+	taken, err := uc.Users.EmailOrPhoneTaken(c.Request.Context(), user.Email, user.Phone)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.Error(err)
 		return
 	}
 
-	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email or phone already exists"})
+	if taken {
+		c.Error(errEmailOrPhoneTaken)
 		return
 	}
 
 	hashedPassword, err := helpers.HashPassword(user.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error hashing password"})
+		c.Error(err)
 		return
 	}
+	// Admins are made with the admin command or by another admin, never
+	// by signing up
 	user.Password = hashedPassword
+	user.Role = models.RoleUser
+	user.EmailVerifiedAt = nil
+	user.LockedAt = nil
+	user.PasswordResetRequired = false
 
-	err = uc.DB.QueryRow(
-		"INSERT INTO users (first_name, last_name, email, password, phone, role) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at",
-		user.FirstName, user.LastName, user.Email, user.Password, user.Phone, user.Role,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err = uc.Users.CreateUser(c.Request.Context(), &user)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(errEmailOrPhoneTaken)
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
-	token, err := helpers.GenerateToken(user.Email, user.ID, user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+	// No session until the address is verified
+	if err := uc.sendVerification(c.Request.Context(), &user); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user, "token": token})
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Account created; check your email for a link to verify your address",
+		"user":    user,
+	})
 }
 
 func (uc *UserController) Login(c *gin.Context) {
@@ -101,70 +96,89 @@ func (uc *UserController) Login(c *gin.Context) {
 		Password string `json:"password" validate:"required"`
 	}
 
-	if err := c.BindJSON(&loginData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !handlers.BindJSON(c, &loginData) {
 		return
 	}
 
-	var user models.User
-	err := uc.DB.QueryRow("SELECT id, first_name, last_name, email, password, role FROM users WHERE email = $1", loginData.Email).Scan(
-		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role,
-	)
-
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	user, err := uc.Users.GetUserByEmail(c.Request.Context(), loginData.Email)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.Unauthorized("invalid_credentials", "Invalid credentials"))
 		return
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.Error(err)
 		return
 	}
 
 	if err := helpers.VerifyPassword(user.Password, loginData.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		c.Error(apperr.Unauthorized("invalid_credentials", "Invalid credentials"))
+		return
+	}
+
+	if !uc.canSignIn(c, user) {
 		return
 	}
 
-	token, err := helpers.GenerateToken(user.Email, user.ID, user.Role)
+	resp, err := uc.startSession(c.Request.Context(), user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		c.Error(err)
 		return
 	}
+	resp["user"] = user
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+	c.JSON(http.StatusOK, resp)
+}
+
+// canSignIn refuses accounts that may not start a session with 403 and a
+// code the client can act on.
+func (uc *UserController) canSignIn(c *gin.Context, user *models.User) bool {
+	switch {
+	case user.LockedAt != nil:
+		c.Error(apperr.Forbidden("account_locked", "This account is locked"))
+	case user.Suspended(time.Now()):
+		msg := "This account is suspended"
+		if user.SuspendedUntil != nil {
+			msg += " until " + user.SuspendedUntil.UTC().Format(time.RFC1123)
+		}
+		c.Error(apperr.Forbidden("account_suspended", msg))
+	case user.EmailVerifiedAt == nil:
+		c.Error(apperr.Forbidden("email_unverified", "Please verify your email address before signing in"))
+	case user.PasswordResetRequired:
+		c.Error(apperr.Forbidden("password_reset_required", "You must reset your password; check your email for a link"))
+	default:
+		return true
+	}
+	return false
+}
+
+var userList = handlers.ListSpec{
+	Sorts:   []string{models.SortCreatedAt, models.SortName},
+	Filters: []string{handlers.FilterSearch, handlers.FilterRole, handlers.FilterStatus},
 }
 
 func (uc *UserController) GetUsers(c *gin.Context) {
-	// Check for admin role if needed
-	role, _ := c.Get("role")
-	if role != "ADMIN" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !requireAdmin(c) {
 		return
 	}
 
-	rows, err := uc.DB.Query("SELECT id, first_name, last_name, email, phone, role, created_at FROM users")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	q, ok := handlers.ParseListQuery(c, userList)
+	if !ok {
 		return
 	}
-	defer rows.Close()
 
-	var users []models.User
-	for rows.Next() {
-		var u models.User
-		if err := rows.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Role, &u.CreatedAt); err != nil {
-			continue
-		}
-		users = append(users, u)
+	users, err := uc.Users.ListUsers(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, handlers.NewPage(users, q, func(u models.User) int { return u.ID }))
 }
 
 func (uc *UserController) GetUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperr.BadRequest("invalid_id", "Invalid ID"))
 		return
 	}
 
@@ -173,20 +187,16 @@ func (uc *UserController) GetUser(c *gin.Context) {
 	role, _ := c.Get("role")
 
 	if role != "ADMIN" && userID != id {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		c.Error(apperr.Forbidden("access_denied", "Access denied"))
 		return
 	}
 
-	var u models.User
-	err = uc.DB.QueryRow("SELECT id, first_name, last_name, email, phone, role, created_at FROM users WHERE id = $1", id).Scan(
-		&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Role, &u.CreatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	u, err := uc.Users.GetUser(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errUserNotFound)
 		return
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
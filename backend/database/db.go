@@ -3,29 +3,20 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"os"
 
 	_ "github.com/lib/pq"
 )
 
-func Connect() (*sql.DB, error) {
-	connStr := os.Getenv("DATABASE_URL")
-	if connStr == "" {
-		fmt.Println("WARNING: DATABASE_URL not set, using localhost")
-		connStr = "host=localhost port=5432 user=postgres password=Strawteddy12 dbname=interview_prep sslmode=disable"
-	} else {
-		fmt.Println("Using DATABASE_URL from environment")
-	}
-
-	db, err := sql.Open("postgres", connStr)
+// Connect opens and pings the PostgreSQL database at url.
+func Connect(url string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", url)
 	if err != nil {
 		return nil, err
 	}
 
 	if err = db.Ping(); err != nil {
-		return nil, err
+		db.Close()
+		return nil, fmt.Errorf("connecting to the database: %w", err)
 	}
-
-	fmt.Println("Database connected successfully")
 	return db, nil
 }
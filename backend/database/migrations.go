@@ -1,60 +1,368 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// RunMigrations creates all necessary tables if they don't exist
-func RunMigrations(db *sql.DB) error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS categories (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(100) NOT NULL UNIQUE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS questions (
-			id SERIAL PRIMARY KEY,
-			category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
-			question TEXT NOT NULL,
-			answer TEXT,
-			difficulty VARCHAR(20),
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_questions_category ON questions(category_id)`,
-		`CREATE TABLE IF NOT EXISTS users (
-			id SERIAL PRIMARY KEY,
-			first_name VARCHAR(100) NOT NULL,
-			last_name VARCHAR(100) NOT NULL,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password VARCHAR(255) NOT NULL,
-			phone VARCHAR(20),
-			role VARCHAR(20) DEFAULT 'USER',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`ALTER TABLE questions ADD COLUMN IF NOT EXISTS context TEXT`,
-		`ALTER TABLE categories ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE CASCADE`,
-		`CREATE TABLE IF NOT EXISTS category_permissions (
-			id SERIAL PRIMARY KEY,
-			category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
-			user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-			status VARCHAR(20) DEFAULT 'PENDING', -- PENDING, APPROVED, REJECTED
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(category_id, user_id)
-		)`,
-	}
-
-	for i, migration := range migrations {
-		_, err := db.Exec(migration)
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the pg_advisory_lock key held while migrating so two
+// instances booting at once don't apply the same version twice.
+const migrationLockID = 7263541
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change embedded from migrations/.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus reports whether a known migration has been applied.
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type appliedMigration struct {
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// LoadMigrations reads the embedded migration files ordered by version.
+// Every version must have both an up and a down file.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := migrationFiles.ReadFile("migrations/" + entry.Name())
 		if err != nil {
-			return fmt.Errorf("migration %d failed: %v", i+1, err)
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has mismatched names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Schema concatenates every up migration. schema.sql at the repository root
+// is generated from it with `go run . migrate schema`.
+func Schema() (string, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("-- Code generated by `go run . migrate schema` from backend/database/migrations. DO NOT EDIT.\n")
+	for _, m := range migrations {
+		fmt.Fprintf(&b, "\n-- %04d_%s\n", m.Version, m.Name)
+		b.WriteString(strings.TrimSpace(m.Up))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// Migrator applies and reverts the embedded migrations, recording progress
+// in the schema_migrations table.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+	// Logger reports each migration applied or reverted.
+	Logger *slog.Logger
+}
+
+func NewMigrator(db *sql.DB, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations, Logger: logger}, nil
+}
+
+// RunMigrations brings the schema up to the latest version. It refuses to
+// run if an already applied migration has been edited since.
+func RunMigrations(db *sql.DB, logger *slog.Logger) error {
+	m, err := NewMigrator(db, logger)
+	if err != nil {
+		return err
+	}
+	return m.Up()
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down reverts the most recently applied migrations, steps at a time.
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	return m.migrate(func(applied map[int]appliedMigration) int {
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+
+		if steps < len(versions) {
+			return versions[len(versions)-steps-1]
+		}
+		return 0
+	})
+}
+
+// To migrates up or down until version is the newest applied migration.
+// Version 0 reverts everything.
+func (m *Migrator) To(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.migrate(func(map[int]appliedMigration) int { return version })
+}
+
+// migrate takes the migration lock, then reverts and applies migrations
+// until the version chosen by target is the newest applied one. target sees
+// the applied set as read under the lock, so a concurrent run can't change
+// it in between.
+func (m *Migrator) migrate(target func(applied map[int]appliedMigration) int) error {
+	conn, err := m.DB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	if err := m.Verify(); err != nil {
+		return err
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	version := target(applied)
+
+	// Revert newest first, then apply oldest first.
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		mig := m.Migrations[i]
+		if _, ok := applied[mig.Version]; ok && mig.Version > version {
+			if err := m.revert(conn, mig); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mig := range m.Migrations {
+		if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+			if err := m.apply(conn, mig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Verify compares the checksum recorded for each applied migration against
+// the embedded file and fails on any difference.
+func (m *Migrator) Verify() error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	for version, a := range applied {
+		mig := m.find(version)
+		if mig == nil {
+			continue
+		}
+		if mig.Checksum != a.Checksum {
+			return fmt.Errorf("migration %04d_%s was edited after it was applied (checksum %s, recorded %s)",
+				mig.Version, mig.Name, mig.Checksum[:12], a.Checksum[:12])
+		}
+	}
+	return nil
+}
+
+// Status lists every embedded migration and whether it has been applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations))
+	for _, mig := range m.Migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			appliedAt := a.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Latest returns the highest embedded migration version.
+func (m *Migrator) Latest() int {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Version returns the highest applied migration version, 0 when none is.
+// Unlike Status it doesn't create the tracking table, so it is safe to call
+// against a database that is only being checked.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.DB.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+func (m *Migrator) applied() (map[int]appliedMigration, error) {
+	rows, err := m.DB.Query("SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
 		}
-		log.Printf("Migration %d completed successfully", i+1)
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) apply(conn *sql.Conn, mig Migration) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(mig.Up); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+		mig.Version, mig.Name, mig.Checksum,
+	); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	m.Logger.Info("applied migration", "version", mig.Version, "name", mig.Name)
+	return nil
+}
+
+func (m *Migrator) revert(conn *sql.Conn, mig Migration) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(mig.Down); err != nil {
+		return fmt.Errorf("reverting migration %04d_%s failed: %v", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", mig.Version); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
 	}
 
-	log.Println("All migrations completed successfully")
+	m.Logger.Info("reverted migration", "version", mig.Version, "name", mig.Name)
 	return nil
 }
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS category_permissions;
+DROP TABLE IF EXISTS questions;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
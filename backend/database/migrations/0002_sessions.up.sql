@@ -0,0 +1,25 @@
+-- One row per login. Access tokens carry the session id as "sid" so that
+-- revoking the session invalidates them before they expire.
+CREATE TABLE sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+
+-- Rotating refresh tokens, stored as SHA-256 hashes. Each refresh marks the
+-- presented token used and issues a successor in the same session; seeing a
+-- used token again revokes the whole session.
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id VARCHAR(64) NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens(session_id);
@@ -0,0 +1,15 @@
+-- Per-user spaced-repetition state for each question, scheduled with SM-2.
+CREATE TABLE review_states (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    last_grade INTEGER NOT NULL DEFAULT 0,
+    due_at TIMESTAMP NOT NULL,
+    last_reviewed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, question_id)
+);
+
+CREATE INDEX idx_review_states_due ON review_states(user_id, due_at);
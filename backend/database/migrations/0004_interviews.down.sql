@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS interview_questions;
+DROP TABLE IF EXISTS interview_sessions;
@@ -0,0 +1,30 @@
+CREATE TABLE interview_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'IN_PROGRESS', -- IN_PROGRESS, COMPLETED
+    time_limit_seconds INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_interview_sessions_user ON interview_sessions(user_id, created_at DESC);
+
+-- The sampled questions of a session together with the user's responses.
+-- Question text is copied so past interviews survive later edits.
+CREATE TABLE interview_questions (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES interview_sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_id INTEGER REFERENCES questions(id) ON DELETE SET NULL,
+    category_id INTEGER,
+    question TEXT NOT NULL,
+    answer TEXT,
+    difficulty VARCHAR(20),
+    served_at TIMESTAMP,
+    answered_at TIMESTAMP,
+    response TEXT,
+    time_taken_ms INTEGER,
+    self_rating INTEGER,
+    timed_out BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE(session_id, position)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_questions_search;
+ALTER TABLE questions DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS idx_questions_created_by;
+ALTER TABLE questions DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,13 @@
+ALTER TABLE questions ADD COLUMN created_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_questions_created_by ON questions(created_by);
+
+-- Weighted so that matches in the question outrank the answer, which
+-- outranks the context.
+ALTER TABLE questions ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(question, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(answer, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(context, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_questions_search ON questions USING GIN (search_vector);
@@ -0,0 +1 @@
+ALTER TABLE category_permissions DROP COLUMN role;
@@ -0,0 +1,6 @@
+-- Approved members of a category get a role instead of blanket write access.
+-- The owner is still categories.user_id; rows here hold the other roles.
+-- Existing approvals keep what they could do before: add questions.
+ALTER TABLE category_permissions
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'contributor'
+    CONSTRAINT category_permissions_role_check CHECK (role IN ('viewer', 'contributor', 'editor'));
@@ -0,0 +1,7 @@
+-- Questions still in the trash are purged rather than silently restored.
+DELETE FROM questions WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_questions_deleted_at;
+ALTER TABLE questions DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE questions DROP COLUMN IF EXISTS deleted_at;
+DROP TABLE IF EXISTS question_revisions;
+DROP FUNCTION IF EXISTS question_revisions_immutable();
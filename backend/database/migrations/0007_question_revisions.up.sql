@@ -0,0 +1,37 @@
+-- Every version of a question is kept as an immutable snapshot. Revision 1
+-- is the question as created; each edit or restore appends the next one.
+CREATE TABLE question_revisions (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    answer TEXT,
+    context TEXT,
+    difficulty VARCHAR(20),
+    edited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    restored_from INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (question_id, revision)
+);
+
+CREATE FUNCTION question_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'question revisions cannot be modified';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER question_revisions_immutable
+    BEFORE UPDATE ON question_revisions
+    FOR EACH ROW EXECUTE FUNCTION question_revisions_immutable();
+
+-- Existing questions start their history at their current content.
+INSERT INTO question_revisions (question_id, revision, question, answer, context, difficulty, edited_by, created_at)
+SELECT id, 1, question, answer, context, difficulty, created_by, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+FROM questions;
+
+-- Deleted questions stay in the trash for 30 days before being purged.
+ALTER TABLE questions
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_questions_deleted_at ON questions(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Tags group questions across the single category each one lives in. A tag
+-- belongs to a category, and its name, stored normalized, is unique there.
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (category_id, name)
+);
+
+-- Serves autocomplete's prefix matches
+CREATE INDEX idx_tags_name ON tags(name text_pattern_ops);
+
+CREATE TABLE question_tags (
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (question_id, tag_id)
+);
+
+CREATE INDEX idx_question_tags_tag ON question_tags(tag_id);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS study_events_roll_up ON study_events;
+DROP FUNCTION IF EXISTS roll_up_study_event();
+DROP TABLE IF EXISTS user_category_stats;
+DROP TABLE IF EXISTS user_daily_stats;
+DROP TABLE IF EXISTS study_events;
@@ -0,0 +1,95 @@
+-- Append-only log of study activity. correct is set for events that say
+-- whether the user knew the answer: review grades and interview answers.
+CREATE TABLE study_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL
+        CONSTRAINT study_events_type_check CHECK (type IN ('viewed', 'answered', 'graded', 'interview_completed')),
+    question_id INTEGER REFERENCES questions(id) ON DELETE SET NULL,
+    category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    interview_id INTEGER REFERENCES interview_sessions(id) ON DELETE SET NULL,
+    difficulty VARCHAR(20),
+    grade INTEGER,
+    correct BOOLEAN,
+    -- UTC, whatever the server's time zone, so days split the same everywhere
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX idx_study_events_user ON study_events(user_id, created_at);
+
+-- Rollups of the log, kept current by the trigger below so stats never scan
+-- the log itself. Days are the date of created_at, so they are UTC days.
+CREATE TABLE user_daily_stats (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    viewed INTEGER NOT NULL DEFAULT 0,
+    answered INTEGER NOT NULL DEFAULT 0,
+    graded INTEGER NOT NULL DEFAULT 0,
+    interviews_completed INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE TABLE user_category_stats (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, category_id)
+);
+
+CREATE FUNCTION roll_up_study_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO user_daily_stats AS s (user_id, day, viewed, answered, graded, interviews_completed, attempts, correct)
+    VALUES (
+        NEW.user_id, NEW.created_at::date,
+        (NEW.type = 'viewed')::int, (NEW.type = 'answered')::int, (NEW.type = 'graded')::int,
+        (NEW.type = 'interview_completed')::int,
+        (NEW.correct IS NOT NULL)::int, COALESCE(NEW.correct, false)::int
+    )
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        viewed = s.viewed + EXCLUDED.viewed,
+        answered = s.answered + EXCLUDED.answered,
+        graded = s.graded + EXCLUDED.graded,
+        interviews_completed = s.interviews_completed + EXCLUDED.interviews_completed,
+        attempts = s.attempts + EXCLUDED.attempts,
+        correct = s.correct + EXCLUDED.correct;
+
+    IF NEW.category_id IS NOT NULL AND NEW.correct IS NOT NULL THEN
+        INSERT INTO user_category_stats AS s (user_id, category_id, attempts, correct)
+        VALUES (NEW.user_id, NEW.category_id, 1, NEW.correct::int)
+        ON CONFLICT (user_id, category_id) DO UPDATE SET
+            attempts = s.attempts + 1,
+            correct = s.correct + EXCLUDED.correct;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER study_events_roll_up
+    AFTER INSERT ON study_events
+    FOR EACH ROW EXECUTE FUNCTION roll_up_study_event();
+
+-- Seed the log with what earlier tables remember: each question's latest
+-- review, interview answers and completed interviews. Those tables stamp
+-- times in the server's zone, so convert them to UTC.
+INSERT INTO study_events (user_id, type, question_id, category_id, difficulty, grade, correct, created_at)
+SELECT r.user_id, 'graded', r.question_id, q.category_id, q.difficulty, r.last_grade, r.last_grade >= 3,
+    (r.last_reviewed_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'utc'
+FROM review_states r
+JOIN questions q ON q.id = r.question_id
+WHERE r.repetitions > 0 OR r.lapses > 0 OR r.last_grade > 0;
+
+INSERT INTO study_events (user_id, type, question_id, category_id, interview_id, difficulty, grade, correct, created_at)
+SELECT s.user_id, 'answered', iq.question_id, c.id, s.id, iq.difficulty, iq.self_rating, iq.self_rating >= 3,
+    (iq.answered_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'utc'
+FROM interview_questions iq
+JOIN interview_sessions s ON s.id = iq.session_id
+LEFT JOIN categories c ON c.id = iq.category_id
+WHERE iq.answered_at IS NOT NULL;
+
+INSERT INTO study_events (user_id, type, interview_id, created_at)
+SELECT user_id, 'interview_completed', id, (completed_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'utc'
+FROM interview_sessions
+WHERE completed_at IS NOT NULL;
@@ -0,0 +1,19 @@
+-- Users confirm their email address before they can sign in. Accounts that
+-- existed before verification was introduced count as verified.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP);
+
+-- Single-use tokens mailed to users to verify their address or reset their
+-- password, stored as SHA-256 hashes. Issuing a token replaces the user's
+-- unused ones for the same purpose.
+CREATE TABLE account_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX idx_account_tokens_user ON account_tokens(user_id, purpose);
@@ -0,0 +1,23 @@
+-- Roles are no longer chosen at signup; only ADMIN and USER exist.
+UPDATE users SET role = 'USER' WHERE role IS NULL OR role NOT IN ('ADMIN', 'USER');
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('ADMIN', 'USER'));
+
+-- Locked accounts can't sign in. A required password reset blocks sign-in
+-- until the user sets a new password through a reset link.
+ALTER TABLE users ADD COLUMN locked_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Administrative changes to accounts. actor_id is NULL for changes made
+-- from the command line.
+CREATE TABLE audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(40) NOT NULL,
+    target_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_target ON audit_log(target_user_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
@@ -0,0 +1,6 @@
+-- Suspended users keep their sessions but can't use them until the
+-- suspension is lifted or suspended_until passes; NULL suspended_until
+-- suspends indefinitely.
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
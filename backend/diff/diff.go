@@ -0,0 +1,134 @@
+// Package diff compares two texts word by word, for showing what changed
+// between revisions of a question.
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// Op is a run of text that both sides share, or that only one side has.
+type Op struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// maxCells caps the size of the LCS table. Past it, the part between the
+// common prefix and suffix is reported as one deletion and one insertion.
+const maxCells = 4 << 20
+
+// Words diffs a against b. Words and the whitespace between them are
+// separate tokens, so the Equal and Delete ops concatenate back to a and the
+// Equal and Insert ops to b.
+func Words(a, b string) []Op {
+	x, y := tokenize(a), tokenize(b)
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var d differ
+	d.add(Equal, x[:prefix]...)
+	common := x[len(x)-suffix:]
+	x, y = x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if len(x)*len(y) > maxCells {
+		d.add(Delete, x...)
+		d.add(Insert, y...)
+	} else {
+		d.lcs(x, y)
+	}
+	d.add(Equal, common...)
+	if d.ops == nil {
+		return []Op{}
+	}
+	return d.ops
+}
+
+type differ struct {
+	ops []Op
+}
+
+// add appends tokens as an op of type kind, merging it into the previous op
+// when that has the same type.
+func (d *differ) add(kind string, tokens ...string) {
+	if len(tokens) == 0 {
+		return
+	}
+	text := strings.Join(tokens, "")
+	if n := len(d.ops); n > 0 && d.ops[n-1].Type == kind {
+		d.ops[n-1].Text += text
+		return
+	}
+	d.ops = append(d.ops, Op{Type: kind, Text: text})
+}
+
+// lcs diffs x and y through their longest common subsequence, preferring
+// deletions before insertions where both are possible.
+func (d *differ) lcs(x, y []string) {
+	n, m := len(x), len(y)
+	// table[i*(m+1)+j] is the LCS length of x[i:] and y[j:]
+	table := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				table[i*(m+1)+j] = table[(i+1)*(m+1)+j+1] + 1
+			case table[(i+1)*(m+1)+j] >= table[i*(m+1)+j+1]:
+				table[i*(m+1)+j] = table[(i+1)*(m+1)+j]
+			default:
+				table[i*(m+1)+j] = table[i*(m+1)+j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			d.add(Equal, x[i])
+			i, j = i+1, j+1
+		case table[(i+1)*(m+1)+j] >= table[i*(m+1)+j+1]:
+			d.add(Delete, x[i])
+			i++
+		default:
+			d.add(Insert, y[j])
+			j++
+		}
+	}
+	d.add(Delete, x[i:]...)
+	d.add(Insert, y[j:]...)
+}
+
+// tokenize splits s into alternating runs of whitespace and non-whitespace.
+func tokenize(s string) []string {
+	var tokens []string
+	start := 0
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != isSpaceAt(s, start) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+func isSpaceAt(s string, i int) bool {
+	for _, r := range s[i:] {
+		return unicode.IsSpace(r)
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package exporter
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"interview-prep/models"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ankiModelID identifies the note type every export uses, so decks
+// imported one after another share it instead of piling up copies.
+const ankiModelID = 1718000000001
+
+// ankiSchema is the version 11 collection layout Anki reads from .apkg files.
+const ankiSchema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+	ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+	conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null,
+	usn integer not null, tags text not null, flds text not null, sfld integer not null,
+	csum integer not null, flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null,
+	mod integer not null, usn integer not null, type integer not null, queue integer not null,
+	due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+	ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+	type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+// ankiWriter fills an Anki collection in a temporary SQLite file and, on
+// Close, zips it into a .apkg with an empty media map.
+type ankiWriter struct {
+	w      io.Writer
+	dir    string
+	db     *sql.DB
+	deckID int64
+	baseID int64
+	now    int64
+	count  int
+}
+
+func newAnkiWriter(w io.Writer, cat *models.Category) (Writer, error) {
+	dir, err := os.MkdirTemp("", "apkg-")
+	if err != nil {
+		return nil, err
+	}
+	aw := &ankiWriter{
+		w:      w,
+		dir:    dir,
+		deckID: ankiDeckID(cat),
+		baseID: time.Now().UnixMilli(),
+		now:    time.Now().Unix(),
+	}
+	if err := aw.init(cat); err != nil {
+		aw.cleanup()
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (aw *ankiWriter) init(cat *models.Category) error {
+	db, err := sql.Open("sqlite", filepath.Join(aw.dir, "collection.anki2"))
+	if err != nil {
+		return err
+	}
+	aw.db = db
+	if _, err := db.Exec(ankiSchema); err != nil {
+		return err
+	}
+
+	conf, models, decks, dconf, err := ankiCollectionJSON(cat.Name, aw.deckID, aw.now)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		"INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		aw.now, aw.now*1000, aw.now*1000, conf, models, decks, dconf,
+	)
+	return err
+}
+
+func (aw *ankiWriter) Write(q models.Question) error {
+	aw.count++
+	id := aw.baseID + int64(aw.count)
+	front := ankiHTML(q.Question)
+	fields := strings.Join([]string{front, ankiHTML(q.Answer), ankiHTML(q.Context)}, "\x1f")
+	tags := ""
+	if q.Difficulty != "" {
+		tags = " " + strings.ReplaceAll(q.Difficulty, " ", "_") + " "
+	}
+
+	_, err := aw.db.Exec(
+		"INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')",
+		id, ankiGUID(q), ankiModelID, aw.now, tags, fields, q.Question, ankiChecksum(q.Question),
+	)
+	if err != nil {
+		return err
+	}
+	// A new card: type and queue 0, due is its position in the new queue
+	_, err = aw.db.Exec(
+		"INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')",
+		id, id, aw.deckID, aw.now, aw.count,
+	)
+	return err
+}
+
+func (aw *ankiWriter) Close() error {
+	defer aw.cleanup()
+	if err := aw.db.Close(); err != nil {
+		return err
+	}
+	aw.db = nil
+
+	zw := zip.NewWriter(aw.w)
+	f, err := os.Open(filepath.Join(aw.dir, "collection.anki2"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entry, err := zw.Create("collection.anki2")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(entry, f); err != nil {
+		return err
+	}
+	media, err := zw.Create("media")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(media, "{}"); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (aw *ankiWriter) cleanup() {
+	if aw.db != nil {
+		aw.db.Close()
+	}
+	os.RemoveAll(aw.dir)
+}
+
+// ankiCollectionJSON builds the col row's configuration, note type, deck
+// and deck options.
+func ankiCollectionJSON(deckName string, deckID, now int64) (conf, models, decks, dconf string, err error) {
+	deck := func(id int64, name string) map[string]any {
+		return map[string]any{
+			"id": id, "name": name, "mod": now, "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+			"collapsed": false, "extendNew": 10, "extendRev": 50,
+			"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+		}
+	}
+	field := func(name string, ord int) map[string]any {
+		return map[string]any{"name": name, "ord": ord, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}}
+	}
+
+	values := []any{
+		map[string]any{
+			"nextPos": 1, "estTimes": true, "activeDecks": []int64{deckID}, "sortType": "noteFld",
+			"timeLim": 0, "sortBackwards": false, "addToCur": true, "curDeck": deckID,
+			"newBust": false, "newSpread": 0, "dueCounts": true, "curModel": ankiModelID, "collapseTime": 1200,
+		},
+		map[string]any{
+			fmt.Sprint(ankiModelID): map[string]any{
+				"id": ankiModelID, "name": "Prepterview Question", "type": 0, "mod": now, "usn": -1,
+				"sortf": 0, "did": deckID, "tags": []string{}, "vers": []int{},
+				"flds": []any{field("Question", 0), field("Answer", 1), field("Context", 2)},
+				"tmpls": []any{map[string]any{
+					"name": "Card 1", "ord": 0, "did": nil, "bqfmt": "", "bafmt": "",
+					"qfmt": "{{Question}}",
+					"afmt": "{{FrontSide}}<hr id=answer>{{Answer}}{{#Context}}<br><br><i>{{Context}}</i>{{/Context}}",
+				}},
+				"css":       ".card { font-family: arial; font-size: 20px; text-align: left; color: black; background-color: white; }",
+				"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+				"latexPost": "\\end{document}",
+				"req":       []any{[]any{0, "all", []int{0}}},
+			},
+		},
+		map[string]any{
+			"1":                deck(1, "Default"),
+			fmt.Sprint(deckID): deck(deckID, deckName),
+		},
+		map[string]any{
+			"1": map[string]any{
+				"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true,
+				"timer": 0, "replayq": true, "dyn": false,
+				"new": map[string]any{
+					"delays": []int{1, 10}, "ints": []int{1, 4, 7}, "initialFactor": 2500,
+					"separate": true, "order": 1, "perDay": 20, "bury": false,
+				},
+				"rev": map[string]any{
+					"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "minSpace": 1,
+					"ivlFct": 1, "maxIvl": 36500, "bury": false,
+				},
+				"lapse": map[string]any{
+					"delays": []int{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 0,
+				},
+			},
+		},
+	}
+
+	out := make([]string, len(values))
+	for i, v := range values {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		out[i] = string(raw)
+	}
+	return out[0], out[1], out[2], out[3], nil
+}
+
+// ankiDeckID derives a stable deck id from the category, so exporting the
+// same category again updates the deck instead of adding another.
+func ankiDeckID(cat *models.Category) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "prepterview-category-%d", cat.ID)
+	return int64(h.Sum64()>>24) + 1
+}
+
+// ankiGUID is stable per question so re-imports update existing notes.
+func ankiGUID(q models.Question) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("prepterview-question-%d", q.ID)))
+	return base64.RawStdEncoding.EncodeToString(sum[:8])
+}
+
+// ankiChecksum is the first 32 bits of the SHA-1 of the sort field, which
+// Anki uses to spot duplicates.
+func ankiChecksum(s string) int64 {
+	sum := sha1.Sum([]byte(s))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+func ankiHTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(strings.TrimSpace(s)), "\n", "<br>")
+}
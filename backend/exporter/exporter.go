@@ -0,0 +1,64 @@
+// Package exporter writes a category's questions out as JSON, CSV, Markdown
+// or an Anki deck. The text formats are the ones the importer reads, so an
+// export can be imported again elsewhere.
+package exporter
+
+import (
+	"fmt"
+	"interview-prep/models"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "md"
+	FormatAnki     = "apkg"
+)
+
+// Writer receives questions one at a time. Close must be called once all
+// have been written; nothing is complete before then.
+type Writer interface {
+	Write(q models.Question) error
+	Close() error
+}
+
+type format struct {
+	contentType string
+	open        func(w io.Writer, cat *models.Category) (Writer, error)
+}
+
+var formats = map[string]format{
+	FormatJSON:     {"application/json", newJSONWriter},
+	FormatCSV:      {"text/csv; charset=utf-8", newCSVWriter},
+	FormatMarkdown: {"text/markdown; charset=utf-8", newMarkdownWriter},
+	FormatAnki:     {"application/octet-stream", newAnkiWriter},
+}
+
+// New starts an export of cat to w.
+func New(name string, w io.Writer, cat *models.Category) (Writer, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q; use json, csv, md or apkg", name)
+	}
+	return f.open(w, cat)
+}
+
+// ContentType is the media type to serve an export in format name with, or
+// "" if the format isn't supported.
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+var unsafeFilename = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Filename suggests a download name for cat exported in format name.
+func Filename(cat *models.Category, name string) string {
+	base := strings.Trim(unsafeFilename.ReplaceAllString(cat.Name, "-"), "-")
+	if base == "" {
+		base = fmt.Sprintf("category-%d", cat.ID)
+	}
+	return base + "." + name
+}
@@ -0,0 +1,97 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"interview-prep/models"
+	"io"
+	"strings"
+)
+
+// record is the portable part of a question, as the importer reads it.
+type record struct {
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+	Context    string `json:"context,omitempty"`
+	Difficulty string `json:"difficulty"`
+}
+
+// jsonWriter streams a JSON array, one element per question.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer, cat *models.Category) (Writer, error) {
+	_, err := io.WriteString(w, "[")
+	return &jsonWriter{w: w}, err
+}
+
+func (jw *jsonWriter) Write(q models.Question) error {
+	raw, err := json.Marshal(record{q.Question, q.Answer, q.Context, q.Difficulty})
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if jw.count == 0 {
+		sep = "\n"
+	}
+	jw.count++
+	_, err = io.WriteString(jw.w, sep+"  "+string(raw))
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	_, err := io.WriteString(jw.w, "\n]\n")
+	return err
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, cat *models.Category) (Writer, error) {
+	cw := csv.NewWriter(w)
+	return &csvWriter{w: cw}, cw.Write([]string{"question", "answer", "context", "difficulty"})
+}
+
+func (cw *csvWriter) Write(q models.Question) error {
+	return cw.w.Write([]string{q.Question, q.Answer, q.Context, q.Difficulty})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// markdownWriter writes a "## " section per question with its difficulty
+// and context in front matter under the heading.
+type markdownWriter struct {
+	w io.Writer
+}
+
+func newMarkdownWriter(w io.Writer, cat *models.Category) (Writer, error) {
+	_, err := fmt.Fprintf(w, "# %s\n", cat.Name)
+	return &markdownWriter{w: w}, err
+}
+
+func (mw *markdownWriter) Write(q models.Question) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n## %s\n---\ndifficulty: %s\n", oneLine(q.Question), q.Difficulty)
+	if q.Context != "" {
+		fmt.Fprintf(&sb, "context: %s\n", oneLine(q.Context))
+	}
+	fmt.Fprintf(&sb, "---\n%s\n", strings.TrimSpace(q.Answer))
+	_, err := io.WriteString(mw.w, sb.String())
+	return err
+}
+
+func (mw *markdownWriter) Close() error {
+	return nil
+}
+
+// oneLine folds text onto a single line for headings and front matter.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
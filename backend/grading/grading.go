@@ -0,0 +1,115 @@
+// Package grading scores a typed answer against a question's stored answer.
+// Graders are interchangeable: Rules matches words and key phrases locally,
+// HTTP asks a language model behind an OpenAI-compatible endpoint, and
+// Chain falls back from one to the next.
+//
+// Key phrases are marked in the stored answer with double brackets, as in
+// "A goroutine is a [[lightweight thread]] managed by the [[Go runtime]]".
+// An answer that misses one is reported as missing that key point.
+package grading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"interview-prep/logging"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// PassingScore is the lowest score that counts as a correct answer.
+const PassingScore = 0.6
+
+// ErrNoReference is returned for a question whose stored answer has nothing
+// to grade against.
+var ErrNoReference = errors.New("no reference answer to grade against")
+
+// Submission is an answer to grade.
+type Submission struct {
+	Question string
+	// Expected is the stored answer, key phrase marks included.
+	Expected string
+	Response string
+}
+
+// Result is a grade between 0 and 1 with the key points the response
+// missed and the name of the grader that produced it.
+type Result struct {
+	Score    float64  `json:"score"`
+	Passed   bool     `json:"passed"`
+	Missed   []string `json:"missed"`
+	Feedback string   `json:"feedback,omitempty"`
+	Grader   string   `json:"grader"`
+}
+
+type Grader interface {
+	Name() string
+	Grade(ctx context.Context, s Submission) (*Result, error)
+}
+
+var keyPhrase = regexp.MustCompile(`\[\[(.+?)\]\]`)
+
+// KeyPoints returns the phrases marked in an answer, in order.
+func KeyPoints(answer string) []string {
+	var points []string
+	for _, m := range keyPhrase.FindAllStringSubmatch(answer, -1) {
+		if p := strings.TrimSpace(m[1]); p != "" {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// hasReference reports whether s has an expected answer with any words in
+// it once the marks are gone.
+func hasReference(s Submission) bool {
+	return len(words(StripMarks(s.Expected))) > 0
+}
+
+// StripMarks removes the key phrase marks from an answer, keeping the
+// phrases themselves.
+func StripMarks(answer string) string {
+	return keyPhrase.ReplaceAllString(answer, "$1")
+}
+
+// finish clamps and rounds the score and fills in the fields every grader
+// sets the same way.
+func finish(r *Result, grader string) *Result {
+	r.Score = math.Round(min(max(r.Score, 0), 1)*100) / 100
+	r.Passed = r.Score >= PassingScore
+	if r.Missed == nil {
+		r.Missed = []string{}
+	}
+	r.Grader = grader
+	return r
+}
+
+// Chain tries each grader in turn and returns the first result. A failing
+// grader is logged and the next one is tried, unless there is no reference
+// answer, which no grader can do anything about.
+type Chain []Grader
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, g := range c {
+		names[i] = g.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c Chain) Grade(ctx context.Context, s Submission) (*Result, error) {
+	var errs []error
+	for _, g := range c {
+		r, err := g.Grade(ctx, s)
+		if err == nil || errors.Is(err, ErrNoReference) {
+			return r, err
+		}
+		logging.FromContext(ctx).Warn("grader failed", "grader", g.Name(), "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", g.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no graders configured")
+	}
+	return nil, errors.Join(errs...)
+}
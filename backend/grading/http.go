@@ -0,0 +1,128 @@
+package grading
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultHTTPTimeout = 30 * time.Second
+
+const systemPrompt = `You grade answers to interview questions. Compare the candidate's answer with the reference answer and reply with only a JSON object:
+{"score": <0 to 1>, "missed": [<key points from the reference the candidate left out or got wrong>], "feedback": "<one or two sentences for the candidate>"}
+Judge meaning, not wording. Every required key point that is missing must appear in "missed".`
+
+// HTTP grades with a language model served over the OpenAI chat
+// completions API, which Ollama, llama.cpp, vLLM and LM Studio all speak.
+// URL is the full endpoint, such as http://localhost:11434/v1/chat/completions.
+type HTTP struct {
+	URL    string
+	Model  string
+	APIKey string
+	Client *http.Client
+}
+
+// NewHTTP returns an HTTP grader whose requests time out after timeout.
+func NewHTTP(url, model, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{URL: url, Model: model, APIKey: apiKey, Client: &http.Client{Timeout: timeout}}
+}
+
+func (g *HTTP) Name() string {
+	if g.Model == "" {
+		return "http"
+	}
+	return "http:" + g.Model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (g *HTTP) Grade(ctx context.Context, s Submission) (*Result, error) {
+	if !hasReference(s) {
+		return nil, ErrNoReference
+	}
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Question:\n%s\n\nReference answer:\n%s\n\n", s.Question, StripMarks(s.Expected))
+	if points := KeyPoints(s.Expected); len(points) > 0 {
+		fmt.Fprintf(&prompt, "Required key points:\n- %s\n\n", strings.Join(points, "\n- "))
+	}
+	fmt.Fprintf(&prompt, "Candidate's answer:\n%s", s.Response)
+
+	body, err := json.Marshal(map[string]any{
+		"model": g.Model,
+		"messages": []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt.String()},
+		},
+		"temperature": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.APIKey)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("grader responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("decoding grader response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("grader returned no choices")
+	}
+
+	// Models tend to wrap the object in prose or a code fence
+	content := completion.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("grader reply has no JSON object: %q", content)
+	}
+	var grade struct {
+		Score    *float64 `json:"score"`
+		Missed   []string `json:"missed"`
+		Feedback string   `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &grade); err != nil {
+		return nil, fmt.Errorf("decoding grade: %w", err)
+	}
+	if grade.Score == nil {
+		return nil, errors.New("grade has no score")
+	}
+	// A score the model made up is no grade; let the next grader answer
+	if !(*grade.Score >= 0 && *grade.Score <= 1) {
+		return nil, fmt.Errorf("grade score %v is outside 0 to 1", *grade.Score)
+	}
+
+	return finish(&Result{Score: *grade.Score, Missed: grade.Missed, Feedback: grade.Feedback}, g.Name()), nil
+}
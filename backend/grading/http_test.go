@@ -0,0 +1,187 @@
+package grading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var goroutine = Submission{
+	Question: "What is a goroutine?",
+	Expected: "A goroutine is a [[lightweight thread]] managed by the [[Go runtime]].",
+	Response: "A lightweight thread.",
+}
+
+// modelServer stands in for a chat completions endpoint, answering every
+// request with reply as the model's message.
+func modelServer(t *testing.T, reply string) *httptest.Server {
+	t.Helper()
+	return completionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+}
+
+func completionServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPGrade(t *testing.T) {
+	var got struct {
+		Model       string        `json:"model"`
+		Messages    []chatMessage `json:"messages"`
+		Temperature float64       `json:"temperature"`
+	}
+	srv := completionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer secret-key" {
+			t.Errorf("request = %s with Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		// Models often wrap the object in prose and a code fence
+		reply := "Here is the grade:\n```json\n" +
+			`{"score": 0.456, "missed": ["Go runtime"], "feedback": "Mention who schedules it."}` + "\n```"
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	})
+
+	g := NewHTTP(srv.URL, "test-model", "secret-key", time.Second)
+	r, err := g.Grade(context.Background(), goroutine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Score != 0.46 || r.Passed || r.Grader != "http:test-model" || r.Feedback != "Mention who schedules it." {
+		t.Errorf("result = %+v", r)
+	}
+	if len(r.Missed) != 1 || r.Missed[0] != "Go runtime" {
+		t.Errorf("missed = %q", r.Missed)
+	}
+
+	if got.Model != "test-model" || got.Temperature != 0 || len(got.Messages) != 2 {
+		t.Fatalf("request body = %+v", got)
+	}
+	prompt := got.Messages[1].Content
+	for _, want := range []string{goroutine.Question, "- lightweight thread\n- Go runtime", goroutine.Response} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "[[") {
+		t.Errorf("prompt keeps the key phrase marks:\n%s", prompt)
+	}
+}
+
+func TestHTTPGradeRejectsBadReplies(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		err   string
+	}{
+		{"prose", "The answer looks fine to me.", "no JSON object"},
+		{"broken JSON", `{"score": 0.8,`, "no JSON object"},
+		{"score of the wrong type", `{"score": "high"}`, "decoding grade"},
+		{"no score", `{"missed": [], "feedback": "Good"}`, "no score"},
+		{"score above 1", `{"score": 8, "missed": []}`, "outside 0 to 1"},
+		{"negative score", `{"score": -0.2, "missed": []}`, "outside 0 to 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHTTP(modelServer(t, tt.reply).URL, "test-model", "", time.Second)
+			r, err := g.Grade(context.Background(), goroutine)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Grade = %+v, %v; want an error containing %q", r, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHTTPGradeFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		err     string
+	}{
+		{"server error", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "model not loaded", http.StatusInternalServerError)
+		}, "500 Internal Server Error: model not loaded"},
+		{"unauthorized", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad key", http.StatusUnauthorized)
+		}, "401 Unauthorized"},
+		{"not JSON", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html>gateway</html>"))
+		}, "decoding grader response"},
+		{"no choices", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"choices": []}`))
+		}, "no choices"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHTTP(completionServer(t, tt.handler).URL, "test-model", "", time.Second)
+			if _, err := g.Grade(context.Background(), goroutine); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHTTPGradeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := completionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	g := NewHTTP(srv.URL, "test-model", "", 50*time.Millisecond)
+	start := time.Now()
+	_, err := g.Grade(context.Background(), goroutine)
+	if err == nil {
+		t.Fatal("no error from a model that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("gave up after %s, want about 50ms", elapsed)
+	}
+
+	// The request's own deadline applies too
+	g = NewHTTP(srv.URL, "test-model", "", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := g.Grade(ctx, goroutine); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context deadline", err)
+	}
+}
+
+func TestChainFallsBack(t *testing.T) {
+	down := NewHTTP(completionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}).URL, "test-model", "", time.Second)
+	up := NewHTTP(modelServer(t, `{"score": 1, "missed": []}`).URL, "test-model", "", time.Second)
+
+	r, err := Chain{down, Rules{}}.Grade(context.Background(), goroutine)
+	if err != nil || r.Grader != "rules" {
+		t.Errorf("Chain{down, rules} = %+v, %v; want a rules grade", r, err)
+	}
+	r, err = Chain{up, Rules{}}.Grade(context.Background(), goroutine)
+	if err != nil || r.Grader != "http:test-model" || !r.Passed {
+		t.Errorf("Chain{up, rules} = %+v, %v; want the model's grade", r, err)
+	}
+	if _, err := (Chain{down, down}).Grade(context.Background(), goroutine); err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("Chain{down, down} err = %v, want the graders' errors", err)
+	}
+	if _, err := (Chain{}).Grade(context.Background(), goroutine); err == nil {
+		t.Error("empty chain graded")
+	}
+}
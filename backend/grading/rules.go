@@ -0,0 +1,188 @@
+package grading
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Answers of at most this many words are compared whole by edit
+	// distance instead of by word overlap.
+	shortAnswerWords = 3
+	// With key phrases marked, this share of the score comes from them and
+	// the rest from word overlap.
+	keyPointWeight = 0.6
+	// Without key phrases, at most this many missing words are reported.
+	maxMissedWords = 10
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "for": true, "from": true, "has": true,
+	"have": true, "in": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "were": true, "which": true, "will": true, "with": true,
+}
+
+// Rules grades locally by matching words, tolerating small typos.
+type Rules struct{}
+
+func (Rules) Name() string { return "rules" }
+
+func (Rules) Grade(ctx context.Context, s Submission) (*Result, error) {
+	if !hasReference(s) {
+		return nil, ErrNoReference
+	}
+	expected := contentWords(StripMarks(s.Expected))
+	response := contentWords(s.Response)
+
+	var r Result
+	if len(expected) <= shortAnswerWords {
+		r.Score = bestWindow(expected, response)
+		if r.Score < PassingScore {
+			r.Missed = []string{strings.TrimSpace(StripMarks(s.Expected))}
+		}
+		return finish(&r, Rules{}.Name()), nil
+	}
+
+	var missedWords []string
+	matched := 0
+	distinct := dedupe(expected)
+	for _, w := range distinct {
+		if containsWord(response, w) {
+			matched++
+		} else {
+			missedWords = append(missedWords, w)
+		}
+	}
+	var overlap float64
+	if len(distinct) > 0 {
+		overlap = float64(matched) / float64(len(distinct))
+	}
+
+	points := KeyPoints(s.Expected)
+	if len(points) == 0 {
+		r.Score = overlap
+		r.Missed = missedWords[:min(len(missedWords), maxMissedWords)]
+		return finish(&r, Rules{}.Name()), nil
+	}
+
+	hit := 0
+	for _, p := range points {
+		if containsPhrase(response, p) {
+			hit++
+		} else {
+			r.Missed = append(r.Missed, p)
+		}
+	}
+	r.Score = keyPointWeight*float64(hit)/float64(len(points)) + (1-keyPointWeight)*overlap
+	return finish(&r, Rules{}.Name()), nil
+}
+
+// words lower-cases s and splits it into runs of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// contentWords is words without stop words, unless that would leave none.
+func contentWords(s string) []string {
+	all := words(s)
+	var content []string
+	for _, w := range all {
+		if !stopWords[w] {
+			content = append(content, w)
+		}
+	}
+	if len(content) == 0 {
+		return all
+	}
+	return content
+}
+
+func dedupe(ws []string) []string {
+	var out []string
+	for _, w := range ws {
+		if !slices.Contains(out, w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// sameWord reports whether two words match, allowing one typo in words of
+// five letters or more and two from eight.
+func sameWord(a, b string) bool {
+	if a == b {
+		return true
+	}
+	n := min(len([]rune(a)), len([]rune(b)))
+	switch {
+	case n >= 8:
+		return levenshtein(a, b) <= 2
+	case n >= 5:
+		return levenshtein(a, b) <= 1
+	}
+	return false
+}
+
+func containsWord(ws []string, w string) bool {
+	return slices.ContainsFunc(ws, func(x string) bool { return sameWord(x, w) })
+}
+
+// containsPhrase reports whether every content word of phrase appears in
+// ws, in any order.
+func containsPhrase(ws []string, phrase string) bool {
+	for _, w := range contentWords(phrase) {
+		if !containsWord(ws, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// bestWindow compares a short expected answer with every run of about as
+// many words in the response, so it can be found inside a longer one.
+func bestWindow(expected, response []string) float64 {
+	want := strings.Join(expected, " ")
+	best := similarity(want, strings.Join(response, " "))
+	for size := max(len(expected)-1, 1); size <= len(expected)+1; size++ {
+		for i := 0; i+size <= len(response); i++ {
+			best = max(best, similarity(want, strings.Join(response[i:i+size], " ")))
+		}
+	}
+	return best
+}
+
+// similarity is 1 minus the edit distance relative to the longer string.
+func similarity(a, b string) float64 {
+	n := max(len([]rune(a)), len([]rune(b)))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(n)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,68 @@
+package grading
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeyPoints(t *testing.T) {
+	got := KeyPoints("A [[lightweight thread]] run by the [[ Go runtime ]] and [[]] nothing else")
+	if want := []string{"lightweight thread", "Go runtime"}; !slices.Equal(got, want) {
+		t.Errorf("KeyPoints = %q, want %q", got, want)
+	}
+	if got := StripMarks(goroutine.Expected); got != "A goroutine is a lightweight thread managed by the Go runtime." {
+		t.Errorf("StripMarks = %q", got)
+	}
+}
+
+func TestRulesGrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		response string
+		passed   bool
+		missed   []string
+	}{
+		{"every key point", goroutine.Expected, "It is a lightweight thread that the Go runtime manages", true, []string{}},
+		{"missing a key point", goroutine.Expected, "A lightweight thread", false, []string{"Go runtime"}},
+		{"off topic", goroutine.Expected, "A type of database index", false, []string{"lightweight thread", "Go runtime"}},
+		{"short answer with a typo", "Mutex", "mutx", true, []string{}},
+		{"short answer wrong", "Mutex", "channel", false, []string{"Mutex"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Rules{}.Grade(context.Background(), Submission{Expected: tt.expected, Response: tt.response})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Passed != tt.passed || r.Grader != "rules" || r.Score < 0 || r.Score > 1 {
+				t.Errorf("result = %+v, want passed %v", r, tt.passed)
+			}
+			if !slices.Equal(r.Missed, tt.missed) {
+				t.Errorf("missed = %q, want %q", r.Missed, tt.missed)
+			}
+		})
+	}
+}
+
+func TestGradeWithoutReference(t *testing.T) {
+	called := false
+	model := NewHTTP(completionServer(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}).URL, "test-model", "", time.Second)
+	for _, expected := range []string{"", "  ", "[[ ]]", "[[]]"} {
+		s := Submission{Question: "What is a goroutine?", Expected: expected, Response: "A lightweight thread"}
+		for _, g := range []Grader{Rules{}, model, Chain{model, Rules{}}} {
+			if r, err := g.Grade(context.Background(), s); !errors.Is(err, ErrNoReference) {
+				t.Errorf("%s graded against %q: %+v, %v", g.Name(), expected, r, err)
+			}
+		}
+	}
+	if called {
+		t.Error("asked the model to grade without a reference answer")
+	}
+}
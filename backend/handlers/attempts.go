@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/grading"
+	"interview-prep/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAttemptLength = 10000
+
+// AttemptQuestion grades a typed answer against the question's answer and
+// returns the score, the key points missed and which grader was used.
+func (h *Handler) AttemptQuestion(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Answer string `json:"answer"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Answer) == "" {
+		c.Error(apperr.BadRequest("answer_required", "Answer is required"))
+		return
+	}
+	if len(req.Answer) > maxAttemptLength {
+		c.Error(apperr.BadRequest("answer_too_long", "Answer is too long"))
+		return
+	}
+
+	q, ok := h.authorizeQuestion(c, id, authz.ReviewQuestion)
+	if !ok {
+		return
+	}
+
+	result, err := h.Grader.Grade(c.Request.Context(), grading.Submission{
+		Question: q.Question,
+		Expected: q.Answer,
+		Response: req.Answer,
+	})
+	if errors.Is(err, grading.ErrNoReference) {
+		c.Error(apperr.Unprocessable("no_reference_answer", "This question has no answer to grade against; add one first"))
+		return
+	} else if err != nil {
+		c.Error(apperr.Unavailable("grading_failed", "Grading failed; please try again", err))
+		return
+	}
+
+	h.record(c, models.StudyEvent{
+		Type:       models.EventAnswered,
+		QuestionID: q.ID,
+		CategoryID: q.CategoryID,
+		Difficulty: q.Difficulty,
+		Correct:    &result.Passed,
+	})
+
+	c.JSON(http.StatusOK, struct {
+		*grading.Result
+		ExpectedAnswer string `json:"expected_answer"`
+	}{result, grading.StripMarks(q.Answer)})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/exporter"
+	"interview-prep/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const exportBatchSize = 200
+
+// ExportCategory streams every question of a category in the requested
+// format. Only users with permission on the category may export it.
+func (h *Handler) ExportCategory(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", exporter.FormatJSON)
+	contentType := exporter.ContentType(format)
+	if contentType == "" {
+		c.Error(apperr.BadRequest("invalid_format", "format must be one of json, csv, md or apkg"))
+		return
+	}
+	ctx := c.Request.Context()
+
+	if _, ok := h.authorize(c, categoryID, authz.ExportCategory); !ok {
+		return
+	}
+	cat, err := h.Categories.GetCategory(ctx, categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Fetch the first batch up front so a failure can still be reported
+	// before the download starts
+	q := models.ListQuery{Sort: models.SortCreatedAt, Limit: exportBatchSize, CategoryID: categoryID}
+	batch, err := h.Questions.ListQuestions(ctx, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exporter.Filename(cat, format)))
+	w, err := exporter.New(format, c.Writer, cat)
+	if err != nil {
+		c.Header("Content-Disposition", "")
+		c.Error(err)
+		return
+	}
+
+	for {
+		page := NewPage(batch, q, func(question models.Question) int { return question.ID })
+		for _, question := range page.Items {
+			if err := w.Write(question); err != nil {
+				c.Error(err)
+				return
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		last := page.Items[len(page.Items)-1]
+		q.After = &models.Cursor{Sort: q.Sort, Value: last.SortValue(q.Sort), ID: last.ID}
+		if batch, err = h.Questions.ListQuestions(ctx, q); err != nil {
+			// Headers are gone; all that's left is to cut the download short
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		c.Error(err)
+	}
+}
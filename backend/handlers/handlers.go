@@ -1,9 +1,13 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/grading"
 	"interview-prep/models"
+	"interview-prep/store"
+	"interview-prep/validation"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,105 +15,172 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	errCategoryNotFound = apperr.NotFound("category_not_found", "Category not found")
+	errQuestionNotFound = apperr.NotFound("question_not_found", "Question not found")
+	errTagNotFound      = apperr.NotFound("tag_not_found", "Tag not found")
+)
+
 type Handler struct {
-	DB *sql.DB
+	Categories  store.CategoryStore
+	Questions   store.QuestionStore
+	Tags        store.TagStore
+	Permissions store.PermissionStore
+	Reviews     store.ReviewStore
+	Interviews  store.InterviewStore
+	Stats       store.StatsStore
+	Grader      grading.Grader
+}
+
+// paramID parses an integer path parameter, answering 400 when it isn't one.
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid_parameter", "Invalid "+name))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt parses an optional integer query parameter, answering 400 when
+// it is present but not a number.
+func queryInt(c *gin.Context, name string, fallback int) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid_parameter", "Invalid "+name))
+		return 0, false
+	}
+	return n, true
+}
+
+// queryDifficulty parses the optional difficulty query parameter,
+// ignoring case, and answers 400 when it names no difficulty.
+func queryDifficulty(c *gin.Context) (string, bool) {
+	raw := c.Query("difficulty")
+	if raw == "" {
+		return "", true
+	}
+	d, ok := models.NormalizeDifficulty(raw)
+	if !ok {
+		c.Error(apperr.BadRequest("invalid_difficulty", "difficulty must be one of "+strings.Join(models.Difficulties, ", ")))
+		return "", false
+	}
+	return d, true
+}
+
+func subject(c *gin.Context) authz.Subject {
+	return authz.Subject{UserID: c.GetInt("user_id"), Admin: c.GetString("role") == "ADMIN"}
+}
+
+// authorize checks action against a category, answering 404 when it
+// doesn't exist and 403 when the policy refuses. It returns the caller's
+// role on the category.
+func (h *Handler) authorize(c *gin.Context, categoryID int, action authz.Action) (string, bool) {
+	return h.decide(c, categoryID, action, nil)
+}
+
+// authorizeQuestion fetches a question and checks action against it. A
+// question the caller isn't allowed to know about is reported as missing.
+func (h *Handler) authorizeQuestion(c *gin.Context, id int, action authz.Action) (*models.Question, bool) {
+	q, err := h.Questions.GetQuestion(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errQuestionNotFound)
+		return nil, false
+	} else if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	if _, ok := h.decide(c, q.CategoryID, action, q); !ok {
+		return nil, false
+	}
+	return q, true
+}
+
+func (h *Handler) decide(c *gin.Context, categoryID int, action authz.Action, q *models.Question) (string, bool) {
+	role, err := h.Permissions.CategoryRole(c.Request.Context(), categoryID, c.GetInt("user_id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errCategoryNotFound)
+		return "", false
+	} else if err != nil {
+		c.Error(err)
+		return "", false
+	}
+
+	switch authz.Decide(subject(c), action, authz.Resource{Role: role, Question: q}) {
+	case authz.NotFound:
+		c.Error(errQuestionNotFound)
+		return "", false
+	case authz.Forbidden:
+		c.Error(apperr.Forbidden("forbidden", authz.Message(action)))
+		return "", false
+	}
+	return role, true
 }
 
 // Categories
+var categoryList = ListSpec{
+	Sorts:   []string{models.SortName, models.SortCreatedAt},
+	Filters: []string{FilterCreator, FilterHasPermission},
+}
+
 func (h *Handler) GetCategories(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-
-	query := `
-		SELECT 
-			c.id, 
-			c.name, 
-			COALESCE(c.user_id, 0), 
-			COALESCE(u.first_name || ' ' || u.last_name, 'Unknown'), 
-			c.created_at,
-			CASE WHEN c.user_id = $1 OR EXISTS(SELECT 1 FROM category_permissions WHERE category_id=c.id AND user_id=$1 AND status='APPROVED') THEN true ELSE false END as has_permission,
-			COALESCE((SELECT status FROM category_permissions WHERE category_id=c.id AND user_id=$1), '') as request_status
-		FROM categories c 
-		LEFT JOIN users u ON c.user_id = u.id 
-		ORDER BY c.name
-	`
-	rows, err := h.DB.Query(query, userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	q, ok := ParseListQuery(c, categoryList)
+	if !ok {
 		return
 	}
-	defer rows.Close()
 
-	categories := []models.Category{}
-	for rows.Next() {
-		var cat models.Category
-		if err := rows.Scan(&cat.ID, &cat.Name, &cat.UserID, &cat.CreatorName, &cat.CreatedAt, &cat.HasPermission, &cat.RequestStatus); err != nil {
-			fmt.Println("Scan error:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		categories = append(categories, cat)
+	categories, err := h.Categories.ListCategories(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	c.JSON(http.StatusOK, NewPage(categories, q, func(cat models.Category) int { return cat.ID }))
 }
 
 func (h *Handler) CreateCategory(c *gin.Context) {
 	var cat models.Category
-	if err := c.BindJSON(&cat); err != nil {
-		fmt.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !BindJSON(c, &cat) {
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperr.Unauthorized("unauthenticated", "User not authenticated"))
 		return
 	}
+	cat.UserID = userID.(int)
 
-	err := h.DB.QueryRow(
-		"INSERT INTO categories (name, user_id) VALUES ($1, $2) RETURNING id, created_at",
-		cat.Name, userID,
-	).Scan(&cat.ID, &cat.CreatedAt)
-
-	if err != nil {
-		fmt.Println("Error inserting category:", err)
-		if strings.Contains(err.Error(), "unique constraint") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Category name already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := h.Categories.CreateCategory(c.Request.Context(), &cat)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(apperr.Conflict("category_exists", "Category name already exists"))
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Fill in creator info for response
-	cat.UserID = userID.(int)
-	// Fetch creator name if needed, or just return basic info
-
+	cat.Role = models.RoleOwner
+	cat.HasPermission = true
 	c.JSON(http.StatusCreated, cat)
 }
 
 func (h *Handler) DeleteCategory(c *gin.Context) {
-	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-
-	// Check ownership
-	var ownerID int
-	err := h.DB.QueryRow("SELECT user_id FROM categories WHERE id=$1", id).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if ownerID != userID.(int) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete categories you created"})
+	if _, ok := h.authorize(c, id, authz.DeleteCategory); !ok {
 		return
 	}
 
-	_, err = h.DB.Exec("DELETE FROM categories WHERE id=$1", id)
-	if err != nil {
-		fmt.Println("Error deleting category:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Categories.DeleteCategory(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -117,72 +188,52 @@ func (h *Handler) DeleteCategory(c *gin.Context) {
 }
 
 // Questions
-func (h *Handler) GetQuestions(c *gin.Context) {
-	categoryID := c.Query("category_id")
-
-	var rows *sql.Rows
-	var err error
-
-	if categoryID != "" {
-		rows, err = h.DB.Query(
-			"SELECT id, category_id, question, answer, COALESCE(context, ''), difficulty, created_at, updated_at FROM questions WHERE category_id = $1 ORDER BY created_at DESC",
-			categoryID,
-		)
-	} else {
-		rows, err = h.DB.Query(
-			"SELECT id, category_id, question, answer, COALESCE(context, ''), difficulty, created_at, updated_at FROM questions ORDER BY created_at DESC",
-		)
-	}
+var questionList = ListSpec{
+	Sorts:       []string{models.SortCreatedAt, models.SortUpdatedAt, models.SortDifficulty},
+	DefaultDesc: true,
+	Filters:     []string{FilterCategory, FilterDifficulty, FilterCreator, FilterTag},
+}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (h *Handler) GetQuestions(c *gin.Context) {
+	lq, ok := ParseListQuery(c, questionList)
+	if !ok {
 		return
 	}
-	defer rows.Close()
-
-	var questions []models.Question
-	for rows.Next() {
-		var q models.Question
-		if err := rows.Scan(&q.ID, &q.CategoryID, &q.Question, &q.Answer, &q.Context, &q.Difficulty, &q.CreatedAt, &q.UpdatedAt); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Without a category, the store leaves out the ones the caller can't read
+	if lq.CategoryID != 0 {
+		if _, ok := h.authorize(c, lq.CategoryID, authz.ViewQuestions); !ok {
 			return
 		}
-		questions = append(questions, q)
 	}
 
-	c.JSON(http.StatusOK, questions)
+	questions, err := h.Questions.ListQuestions(c.Request.Context(), lq)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPage(questions, lq, func(q models.Question) int { return q.ID }))
 }
 
 func (h *Handler) CreateQuestion(c *gin.Context) {
 	var q models.Question
-	if err := c.BindJSON(&q); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !BindJSON(c, &q) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-
-	// Check permission: Owner OR Approved Request
-	var hasPermission bool
-	err := h.DB.QueryRow(`
-		SELECT EXISTS (
-			SELECT 1 FROM categories WHERE id = $1 AND user_id = $2
-			UNION
-			SELECT 1 FROM category_permissions WHERE category_id = $1 AND user_id = $2 AND status = 'APPROVED'
-		)`, q.CategoryID, userID).Scan(&hasPermission)
+	userID := c.GetInt("user_id")
+	q.CreatedBy = userID
 
-	if err != nil || !hasPermission {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to add questions to this category"})
+	var ok bool
+	if q.Tags, ok = normalizeTags(c, q.Tags); !ok {
+		return
+	}
+	if _, ok := h.authorize(c, q.CategoryID, authz.CreateQuestion); !ok {
 		return
 	}
 
-	err = h.DB.QueryRow(
-		"INSERT INTO questions (category_id, question, answer, context, difficulty) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at",
-		q.CategoryID, q.Question, q.Answer, q.Context, q.Difficulty,
-	).Scan(&q.ID, &q.CreatedAt, &q.UpdatedAt)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Questions.CreateQuestion(c.Request.Context(), &q); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -190,31 +241,123 @@ func (h *Handler) CreateQuestion(c *gin.Context) {
 }
 
 func (h *Handler) UpdateQuestion(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	var q models.Question
-	if err := c.BindJSON(&q); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	// Authorize first so callers who can't see the question learn nothing
+	// of it from the validation errors
+	if _, ok := h.authorizeQuestion(c, id, authz.EditQuestion); !ok {
+		return
+	}
+	// A replacement sets every field; leaving one out would wipe it, so
+	// partial updates go through PATCH
+	var patch models.QuestionPatch
+	if !BindJSON(c, &patch) {
+		return
+	}
+	var missing []apperr.FieldError
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"question", patch.Question != nil},
+		{"answer", patch.Answer != nil},
+		{"context", patch.Context != nil},
+		{"difficulty", patch.Difficulty != nil},
+		{"tags", patch.Tags != nil},
+	} {
+		if !f.set {
+			missing = append(missing, apperr.FieldError{Field: f.name, Message: validation.Label(f.name) + " is required"})
+		}
+	}
+	if len(missing) > 0 {
+		c.Error(apperr.Unprocessable("incomplete_question", "PUT replaces the whole question; send every field or use PATCH", missing...))
+		return
+	}
+	q := models.Question{ID: id}
+	patch.Apply(&q)
+	if q.Tags, ok = normalizeTags(c, q.Tags); !ok {
+		return
+	}
+	q.Normalize()
+	if !Validate(c, &q) {
 		return
 	}
 
-	_, err := h.DB.Exec(
-		"UPDATE questions SET question=$1, answer=$2,context=$3, difficulty=$4, updated_at=CURRENT_TIMESTAMP WHERE id=$5",
-		q.Question, q.Answer, q.Context, q.Difficulty, id,
-	)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := h.Questions.UpdateQuestion(c.Request.Context(), &q, c.GetInt("user_id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errQuestionNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "updated successfully"})
 }
 
+// PatchQuestion updates the fields the request sets and leaves the rest,
+// answering with the question as saved.
+func (h *Handler) PatchQuestion(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var patch models.QuestionPatch
+	if !BindJSON(c, &patch) {
+		return
+	}
+	if patch.Tags, ok = normalizeTags(c, patch.Tags); !ok {
+		return
+	}
+
+	q, ok := h.authorizeQuestion(c, id, authz.EditQuestion)
+	if !ok {
+		return
+	}
+	patch.Apply(q)
+	q.Normalize()
+	if !Validate(c, q) {
+		return
+	}
+
+	err := h.Questions.UpdateQuestion(c.Request.Context(), q, c.GetInt("user_id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errQuestionNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	if q.Tags == nil {
+		if q, err = h.Questions.GetQuestion(c.Request.Context(), id); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, q)
+}
+
+// DeleteQuestion moves a question to the trash, from which it can be
+// restored for TrashRetention.
 func (h *Handler) DeleteQuestion(c *gin.Context) {
-	id := c.Param("id")
-	_, err := h.DB.Exec("DELETE FROM questions WHERE id=$1", id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.authorizeQuestion(c, id, authz.DeleteQuestion); !ok {
+		return
+	}
+
+	err := h.Questions.DeleteQuestion(c.Request.Context(), id, c.GetInt("user_id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errQuestionNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -223,24 +366,21 @@ func (h *Handler) DeleteQuestion(c *gin.Context) {
 
 // Permissions
 func (h *Handler) RequestAccess(c *gin.Context) {
-	categoryID := c.Param("id")
-	userID, _ := c.Get("user_id")
-
-	// Check if request already exists
-	var exists bool
-	err := h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM category_permissions WHERE category_id=$1 AND user_id=$2)", categoryID, userID).Scan(&exists)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if exists {
-		c.JSON(http.StatusConflict, gin.H{"error": "Request already exists"})
+	categoryID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
+	userID := c.GetInt("user_id")
 
-	_, err = h.DB.Exec("INSERT INTO category_permissions (category_id, user_id) VALUES ($1, $2)", categoryID, userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := h.Permissions.CreateRequest(c.Request.Context(), categoryID, userID)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(apperr.Conflict("request_exists", "Request already exists"))
+		return
+	} else if errors.Is(err, store.ErrNotFound) {
+		c.Error(errCategoryNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -248,94 +388,78 @@ func (h *Handler) RequestAccess(c *gin.Context) {
 }
 
 func (h *Handler) GetRequests(c *gin.Context) {
-	categoryID := c.Param("id")
-	userID, _ := c.Get("user_id")
-
-	// Verify ownership
-	var ownerID int
-	err := h.DB.QueryRow("SELECT user_id FROM categories WHERE id=$1", categoryID).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	categoryID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-	if ownerID != userID.(int) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only owner can view requests"})
+
+	if _, ok := h.authorize(c, categoryID, authz.ManageRequests); !ok {
 		return
 	}
 
-	rows, err := h.DB.Query(`
-		SELECT p.id, u.first_name, u.last_name, u.email, p.status, p.created_at
-		FROM category_permissions p
-		JOIN users u ON p.user_id = u.id
-		WHERE p.category_id = $1 AND p.status = 'PENDING'
-	`, categoryID)
+	requests, err := h.Permissions.ListPendingRequests(c.Request.Context(), categoryID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	defer rows.Close()
-
-	var requests []gin.H
-	for rows.Next() {
-		var r struct {
-			ID        int
-			FirstName string
-			LastName  string
-			Email     string
-			Status    string
-			CreatedAt string
-		}
-		if err := rows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.Email, &r.Status, &r.CreatedAt); err != nil {
-			continue
-		}
-		requests = append(requests, gin.H{
-			"id": r.ID,
-			"user": gin.H{
-				"first_name": r.FirstName,
-				"last_name":  r.LastName,
-				"email":      r.Email,
-			},
-			"status":     r.Status,
-			"created_at": r.CreatedAt,
-		})
-	}
 
 	c.JSON(http.StatusOK, requests)
 }
 
 func (h *Handler) RespondToRequest(c *gin.Context) {
-	requestID := c.Param("requestId")
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	requestID, ok := paramID(c, "requestId")
+	if !ok {
+		return
+	}
 	var req struct {
 		Status string `json:"status"` // APPROVED or REJECTED
+		Role   string `json:"role"`   // granted on approval, contributor unless set
 	}
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !BindJSON(c, &req) {
+		return
+	}
+	if req.Status != "APPROVED" && req.Status != "REJECTED" {
+		c.Error(apperr.BadRequest("invalid_status", "status must be APPROVED or REJECTED"))
 		return
 	}
 
-	// Verify ownership of the category this request belongs to
-	userID, _ := c.Get("user_id")
-	var ownerID int
-	err := h.DB.QueryRow(`
-		SELECT c.user_id 
-		FROM category_permissions p 
-		JOIN categories c ON p.category_id = c.id 
-		WHERE p.id = $1
-	`, requestID).Scan(&ownerID)
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+	// Editors manage requests; only the owner can hand out editor. The
+	// caller is checked first so others learn nothing about the request.
+	role, ok := h.authorize(c, categoryID, authz.ManageRequests)
+	if !ok {
 		return
 	}
 
-	if ownerID != userID.(int) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized"})
+	accessRequest, err := h.Permissions.GetRequest(c.Request.Context(), requestID)
+	if errors.Is(err, store.ErrNotFound) || err == nil && accessRequest.CategoryID != categoryID {
+		c.Error(apperr.NotFound("request_not_found", "Request not found"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	if accessRequest.Status != "PENDING" {
+		c.Error(apperr.Conflict("request_answered", "Request was already answered; manage members instead"))
+		return
+	}
+	switch req.Role {
+	case "", models.RoleViewer, models.RoleContributor:
+	case models.RoleEditor:
+		if !authz.Can(subject(c), authz.ManageMembers, authz.Resource{Role: role}) {
+			c.Error(apperr.Forbidden("owner_only", "Only the owner can grant the editor role"))
+			return
+		}
+	default:
+		c.Error(apperr.BadRequest("invalid_role", "role must be viewer, contributor or editor"))
 		return
 	}
 
-	_, err = h.DB.Exec("UPDATE category_permissions SET status=$1 WHERE id=$2", req.Status, requestID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Permissions.UpdateRequestStatus(c.Request.Context(), requestID, req.Status, req.Role); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/database"
+	"interview-prep/logging"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyTimeout bounds the database checks of a readiness probe.
+const readyTimeout = 2 * time.Second
+
+// Health answers the liveness and readiness probes of the orchestrator
+// running the server.
+type Health struct {
+	DB       *sql.DB
+	Migrator *database.Migrator
+
+	draining atomic.Bool
+}
+
+// Drain makes readiness fail from now on, so the orchestrator stops
+// routing new requests here while the ones in flight finish.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Live reports that the process is up and serving. It checks nothing else,
+// so a database outage doesn't get the server restarted.
+func (h *Health) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready reports whether the server should receive traffic: it isn't
+// shutting down, the database answers, and its schema is at the latest
+// migration. It answers 503 with the failing checks otherwise.
+func (h *Health) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	checks := gin.H{"database": "ok"}
+	ready := true
+	if err := h.DB.PingContext(ctx); err != nil {
+		logging.FromContext(ctx).Error("readiness: database ping failed", "error", err)
+		checks["database"] = "unreachable"
+		ready = false
+	}
+
+	migrations := gin.H{"latest": h.Migrator.Latest()}
+	if version, err := h.Migrator.Version(ctx); err != nil {
+		logging.FromContext(ctx).Error("readiness: reading the migration version failed", "error", err)
+		migrations["status"] = "unknown"
+		ready = false
+	} else {
+		migrations["current"] = version
+		migrations["status"] = "ok"
+		if version < h.Migrator.Latest() {
+			migrations["status"] = "pending"
+			ready = false
+		}
+	}
+	checks["migrations"] = migrations
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/importer"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxImportBytes = 5 << 20
+	maxImportRows  = 5000
+)
+
+// ImportQuestions adds questions to a category in bulk from a CSV, JSON or
+// Markdown file, sent either as the raw body or as the "file" field of a
+// multipart form. With dry_run=true the file is only validated. Otherwise
+// it is imported in a single transaction, and only if every row is valid.
+func (h *Handler) ImportQuestions(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	userID := c.GetInt("user_id")
+	ctx := c.Request.Context()
+
+	if _, ok := h.authorize(c, categoryID, authz.CreateQuestion); !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	body, filename, contentType := io.Reader(c.Request.Body), "", c.ContentType()
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		defer f.Close()
+		body, filename, contentType = f, fh.Filename, fh.Header.Get("Content-Type")
+	}
+
+	format, err := importer.DetectFormat(c.Query("format"), filename, contentType)
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid_import", err.Error()))
+		return
+	}
+	result, err := importer.Parse(format, body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.Error(apperr.New(apperr.KindTooLarge, "import_too_large", "Import files are limited to 5 MB"))
+		return
+	} else if err != nil {
+		c.Error(apperr.BadRequest("invalid_import", err.Error()))
+		return
+	}
+	if result.Total == 0 {
+		c.Error(apperr.BadRequest("import_empty", "No questions found in the file"))
+		return
+	}
+	if result.Total > maxImportRows {
+		c.Error(apperr.BadRequest("import_too_many", "Import files are limited to 5000 questions"))
+		return
+	}
+
+	for i := range result.Questions {
+		result.Questions[i].CategoryID = categoryID
+		result.Questions[i].CreatedBy = userID
+	}
+
+	report := gin.H{
+		"dry_run": dryRun,
+		"format":  format,
+		"total":   result.Total,
+		"valid":   len(result.Questions),
+		"errors":  result.Errors,
+	}
+	if dryRun {
+		report["questions"] = result.Questions
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	if err := h.Questions.ImportQuestions(ctx, result.Questions); err != nil {
+		c.Error(err)
+		return
+	}
+
+	report["imported"] = len(result.Questions)
+	report["questions"] = result.Questions
+	c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/interview"
+	"interview-prep/models"
+	"interview-prep/store"
+	"math/rand"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInterview starts a mock interview by sampling questions from the
+// requested categories according to the difficulty mix.
+func (h *Handler) CreateInterview(c *gin.Context) {
+	var plan interview.Plan
+	if !BindJSON(c, &plan) {
+		return
+	}
+	if err := plan.Normalize(); err != nil {
+		c.Error(apperr.BadRequest("invalid_plan", err.Error()))
+		return
+	}
+	slices.Sort(plan.CategoryIDs)
+	plan.CategoryIDs = slices.Compact(plan.CategoryIDs)
+
+	userID := c.GetInt("user_id")
+	ctx := c.Request.Context()
+
+	for _, categoryID := range plan.CategoryIDs {
+		if _, ok := h.authorize(c, categoryID, authz.ViewQuestions); !ok {
+			return
+		}
+	}
+
+	var sampled []models.Question
+	var seen []int
+	for _, bucket := range plan.Buckets() {
+		questions, err := h.Interviews.SampleQuestions(ctx, plan.CategoryIDs, bucket.Difficulty, bucket.Count, seen)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		for _, q := range questions {
+			seen = append(seen, q.ID)
+		}
+		sampled = append(sampled, questions...)
+	}
+	if len(sampled) == 0 {
+		c.Error(apperr.BadRequest("no_matching_questions", "No questions match the requested categories and difficulties"))
+		return
+	}
+	rand.Shuffle(len(sampled), func(i, j int) { sampled[i], sampled[j] = sampled[j], sampled[i] })
+
+	session := models.InterviewSession{
+		UserID:           userID,
+		Status:           models.InterviewInProgress,
+		TimeLimitSeconds: plan.TimeLimitSeconds,
+	}
+	for i, q := range sampled {
+		session.Questions = append(session.Questions, models.InterviewQuestion{
+			Position:   i + 1,
+			QuestionID: q.ID,
+			CategoryID: q.CategoryID,
+			Question:   q.Question,
+			Answer:     q.Answer,
+			Difficulty: q.Difficulty,
+		})
+	}
+
+	if err := h.Interviews.CreateInterview(ctx, &session); err != nil {
+		c.Error(err)
+		return
+	}
+
+	hideUnanswered(&session)
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) GetInterviews(c *gin.Context) {
+	sessions, err := h.Interviews.ListInterviews(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetInterview returns a session with its questions, responses and report.
+func (h *Handler) GetInterview(c *gin.Context) {
+	session, ok := h.loadInterview(c)
+	if !ok {
+		return
+	}
+
+	summary := interview.Summarize(session)
+	hideUnanswered(session)
+	c.JSON(http.StatusOK, gin.H{"interview": session, "summary": summary})
+}
+
+// NextInterviewQuestion serves the current question and starts its clock the
+// first time it is fetched.
+func (h *Handler) NextInterviewQuestion(c *gin.Context) {
+	session, ok := h.loadInterview(c)
+	if !ok {
+		return
+	}
+
+	current := interview.Current(session)
+	if current == nil {
+		c.JSON(http.StatusOK, gin.H{"done": true, "total": len(session.Questions)})
+		return
+	}
+
+	if current.ServedAt == nil {
+		now := time.Now()
+		if err := h.Interviews.MarkInterviewQuestionServed(c.Request.Context(), current.ID, now); err != nil && !errors.Is(err, store.ErrNotFound) {
+			c.Error(err)
+			return
+		}
+		current.ServedAt = &now
+	}
+
+	deadline := interview.Deadline(session, current)
+	remaining := int(time.Until(deadline).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	current.Answer = ""
+	c.JSON(http.StatusOK, gin.H{
+		"done":              false,
+		"question":          current,
+		"total":             len(session.Questions),
+		"deadline":          deadline,
+		"remaining_seconds": remaining,
+	})
+}
+
+// SubmitInterviewAnswer records the response to the current question. Late
+// answers are kept but flagged as timed out.
+func (h *Handler) SubmitInterviewAnswer(c *gin.Context) {
+	session, ok := h.loadInterview(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Answer     string `json:"answer"`
+		SelfRating int    `json:"self_rating"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	if req.SelfRating < interview.MinSelfRating || req.SelfRating > interview.MaxSelfRating {
+		c.Error(apperr.BadRequest("invalid_self_rating", "self_rating must be between 1 and 5"))
+		return
+	}
+
+	current := interview.Current(session)
+	if current == nil {
+		c.Error(apperr.Conflict("no_pending_question", "No question is awaiting an answer"))
+		return
+	}
+	if current.ServedAt == nil {
+		c.Error(apperr.Conflict("question_not_fetched", "Fetch the question from /next before answering"))
+		return
+	}
+
+	now := time.Now()
+	timeTaken := int(now.Sub(*current.ServedAt).Milliseconds())
+	current.AnsweredAt = &now
+	current.Response = req.Answer
+	current.TimeTakenMs = &timeTaken
+	current.SelfRating = &req.SelfRating
+	current.TimedOut = now.After(interview.Deadline(session, current))
+
+	err := h.Interviews.SaveInterviewResponse(c.Request.Context(), current)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.Conflict("already_answered", "Question was already answered"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	correct := req.SelfRating >= interview.PassingSelfRating
+	h.record(c, models.StudyEvent{
+		Type:        models.EventAnswered,
+		QuestionID:  current.QuestionID,
+		CategoryID:  current.CategoryID,
+		InterviewID: session.ID,
+		Difficulty:  current.Difficulty,
+		Grade:       &req.SelfRating,
+		Correct:     &correct,
+	})
+
+	c.JSON(http.StatusOK, current)
+}
+
+// CompleteInterview closes the session, leaving unanswered questions as
+// skipped, and returns the summary report.
+func (h *Handler) CompleteInterview(c *gin.Context) {
+	session, ok := h.loadInterview(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	err := h.Interviews.CompleteInterview(c.Request.Context(), session.ID, now)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.Conflict("interview_completed", "Interview is already completed"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	session.Status = models.InterviewCompleted
+	session.CompletedAt = &now
+	h.record(c, models.StudyEvent{Type: models.EventInterviewCompleted, InterviewID: session.ID})
+
+	c.JSON(http.StatusOK, gin.H{"interview": session, "summary": interview.Summarize(session)})
+}
+
+// loadInterview fetches the session named in the path. Other users'
+// sessions are reported as missing.
+func (h *Handler) loadInterview(c *gin.Context) (*models.InterviewSession, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	session, err := h.Interviews.GetInterview(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && session.UserID != c.GetInt("user_id")) {
+		c.Error(apperr.NotFound("interview_not_found", "Interview not found"))
+		return nil, false
+	} else if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	return session, true
+}
+
+// hideUnanswered blanks model answers the user hasn't responded to yet while
+// the interview is running.
+func hideUnanswered(s *models.InterviewSession) {
+	if s.Status != models.InterviewInProgress {
+		return
+	}
+	for i := range s.Questions {
+		if s.Questions[i].AnsweredAt == nil {
+			s.Questions[i].Answer = ""
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMembers lists a category's approved members and their roles.
+func (h *Handler) GetMembers(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if _, ok := h.authorize(c, categoryID, authz.ManageRequests); !ok {
+		return
+	}
+
+	members, err := h.Permissions.ListMembers(c.Request.Context(), categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SetMemberRole lets the owner grant or change a user's role, whether or
+// not they asked for access.
+func (h *Handler) SetMemberRole(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := paramID(c, "userId")
+	if !ok {
+		return
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	switch req.Role {
+	case models.RoleViewer, models.RoleContributor, models.RoleEditor:
+	case models.RoleOwner:
+		c.Error(apperr.BadRequest("use_transfer", "Use the transfer endpoint to change the owner"))
+		return
+	default:
+		c.Error(apperr.BadRequest("invalid_role", "role must be viewer, contributor or editor"))
+		return
+	}
+
+	if _, ok := h.authorize(c, categoryID, authz.ManageMembers); !ok {
+		return
+	}
+	if userID == c.GetInt("user_id") {
+		c.Error(apperr.BadRequest("owner_role_fixed", "The owner's role can't be changed; transfer ownership instead"))
+		return
+	}
+
+	err := h.Permissions.SetMemberRole(c.Request.Context(), categoryID, userID, req.Role)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("user_not_found", "User not found"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated", "user_id": userID, "role": req.Role})
+}
+
+func (h *Handler) RemoveMember(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := paramID(c, "userId")
+	if !ok {
+		return
+	}
+	if _, ok := h.authorize(c, categoryID, authz.ManageMembers); !ok {
+		return
+	}
+
+	err := h.Permissions.RemoveMember(c.Request.Context(), categoryID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("member_not_found", "Member not found"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// TransferCategory hands the category to another user. The previous owner
+// stays on as an editor.
+func (h *Handler) TransferCategory(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID int `json:"user_id"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+
+	if _, ok := h.authorize(c, categoryID, authz.TransferCategory); !ok {
+		return
+	}
+	if req.UserID == c.GetInt("user_id") {
+		c.Error(apperr.BadRequest("already_owner", "You already own this category"))
+		return
+	}
+
+	err := h.Categories.TransferCategory(c.Request.Context(), categoryID, req.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("user_not_found", "User not found"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred"})
+}
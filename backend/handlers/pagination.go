@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"interview-prep/apperr"
+	"interview-prep/models"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// Filters a list endpoint can opt into, named after their query parameter.
+const (
+	FilterCategory      = "category_id"
+	FilterDifficulty    = "difficulty"
+	FilterCreator       = "created_by"
+	FilterHasPermission = "has_permission"
+	FilterTag           = "tag" // repeatable; rows must carry every tag given
+	FilterUser          = "user_id"
+	FilterSearch        = "q"
+	FilterRole          = "role"   // ADMIN or USER
+	FilterStatus        = "status" // an account state, such as suspended
+)
+
+var userStatuses = []string{models.UserActive, models.UserLocked, models.UserSuspended, models.UserUnverified}
+
+// ListSpec declares what a list endpoint accepts. The first sort key is the
+// default.
+type ListSpec struct {
+	Sorts       []string
+	DefaultDesc bool
+	Filters     []string
+}
+
+// ParseListQuery reads limit, cursor, sort, order and the spec's filters
+// from the query string, answering 400 on anything it can't use.
+func ParseListQuery(c *gin.Context, spec ListSpec) (models.ListQuery, bool) {
+	q := models.ListQuery{Sort: spec.Sorts[0], Desc: spec.DefaultDesc, UserID: c.GetInt("user_id"), Admin: subject(c).Admin}
+
+	var ok bool
+	if q.Limit, ok = queryInt(c, "limit", defaultPageLimit); !ok {
+		return q, false
+	}
+	if q.Limit < 1 || q.Limit > maxPageLimit {
+		c.Error(apperr.BadRequest("invalid_limit", "limit must be between 1 and 100"))
+		return q, false
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		if !slices.Contains(spec.Sorts, sort) {
+			c.Error(apperr.BadRequest("invalid_sort", "sort must be one of "+strings.Join(spec.Sorts, ", ")))
+			return q, false
+		}
+		q.Sort = sort
+	}
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		q.Desc = false
+	case "desc":
+		q.Desc = true
+	default:
+		c.Error(apperr.BadRequest("invalid_order", "order must be asc or desc"))
+		return q, false
+	}
+
+	for _, filter := range spec.Filters {
+		switch filter {
+		case FilterCategory:
+			q.CategoryID, ok = queryInt(c, filter, 0)
+		case FilterCreator:
+			q.CreatedBy, ok = queryInt(c, filter, 0)
+		case FilterUser:
+			q.TargetUserID, ok = queryInt(c, filter, 0)
+		case FilterDifficulty:
+			q.Difficulty, ok = queryDifficulty(c)
+		case FilterSearch:
+			q.Search = strings.TrimSpace(c.Query(filter))
+		case FilterRole:
+			q.Role = c.Query(filter)
+			if q.Role != "" && q.Role != models.RoleAdmin && q.Role != models.RoleUser {
+				c.Error(apperr.BadRequest("invalid_role", "role must be ADMIN or USER"))
+				return q, false
+			}
+		case FilterStatus:
+			q.Status = c.Query(filter)
+			if q.Status != "" && !slices.Contains(userStatuses, q.Status) {
+				c.Error(apperr.BadRequest("invalid_status", "status must be one of "+strings.Join(userStatuses, ", ")))
+				return q, false
+			}
+		case FilterTag:
+			q.Tags, ok = normalizeTags(c, c.QueryArray(filter))
+		case FilterHasPermission:
+			if raw := c.Query(filter); raw != "" {
+				b, err := strconv.ParseBool(raw)
+				if err != nil {
+					c.Error(apperr.BadRequest("invalid_parameter", "Invalid "+filter))
+					return q, false
+				}
+				q.HasPermission = &b
+			}
+		}
+		if !ok {
+			return q, false
+		}
+	}
+
+	// A cursor only makes sense for the ordering it was issued under
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil || cursor.Sort != q.Sort || cursor.Desc != q.Desc {
+			c.Error(apperr.BadRequest("invalid_cursor", "Invalid cursor"))
+			return q, false
+		}
+		q.After = cursor
+	}
+	return q, true
+}
+
+// NewPage trims the extra row the store fetched to look ahead and, when
+// there was one, points the next cursor at the last row kept.
+func NewPage[T interface{ SortValue(string) string }](items []T, q models.ListQuery, id func(T) int) models.Page[T] {
+	page := models.Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		last := page.Items[q.Limit-1]
+		page.NextCursor = encodeCursor(models.Cursor{Sort: q.Sort, Desc: q.Desc, Value: last.SortValue(q.Sort), ID: id(last)})
+	}
+	return page
+}
+
+func encodeCursor(cursor models.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*models.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cursor models.Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
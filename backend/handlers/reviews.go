@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/models"
+	"interview-prep/review"
+	"interview-prep/store"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDueLimit = 20
+	maxDueLimit     = 100
+)
+
+// GetDueReviews lists the questions the caller should study next across the
+// categories they have permission on.
+func (h *Handler) GetDueReviews(c *gin.Context) {
+	limit, ok := queryInt(c, "limit", defaultDueLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > maxDueLimit {
+		c.Error(apperr.BadRequest("invalid_limit", "limit must be between 1 and 100"))
+		return
+	}
+
+	categoryID, ok := queryInt(c, "category_id", 0)
+	if !ok {
+		return
+	}
+
+	due, err := h.Reviews.ListDueReviews(c.Request.Context(), c.GetInt("user_id"), categoryID, time.Now(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, due)
+}
+
+// SubmitReview records a 0-5 grade for a question and reschedules it.
+func (h *Handler) SubmitReview(c *gin.Context) {
+	questionID, ok := paramID(c, "questionId")
+	if !ok {
+		return
+	}
+	var req struct {
+		Grade *int `json:"grade"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	if req.Grade == nil || *req.Grade < review.MinGrade || *req.Grade > review.MaxGrade {
+		c.Error(apperr.BadRequest("invalid_grade", "grade must be between 0 and 5"))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	q, ok := h.authorizeQuestion(c, questionID, authz.ReviewQuestion)
+	if !ok {
+		return
+	}
+
+	state, err := h.Reviews.GetReviewState(c.Request.Context(), userID, questionID)
+	if errors.Is(err, store.ErrNotFound) {
+		fresh := review.NewState(userID, questionID)
+		state = &fresh
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	next := review.Schedule(*state, *req.Grade, time.Now())
+	if err := h.Reviews.SaveReviewState(c.Request.Context(), &next); err != nil {
+		c.Error(err)
+		return
+	}
+
+	correct := *req.Grade >= review.PassingGrade
+	h.record(c, models.StudyEvent{
+		Type:       models.EventGraded,
+		QuestionID: q.ID,
+		CategoryID: q.CategoryID,
+		Difficulty: q.Difficulty,
+		Grade:      req.Grade,
+		Correct:    &correct,
+	})
+
+	c.JSON(http.StatusOK, next)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/diff"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRevisions lists every saved version of a question, newest first.
+func (h *Handler) GetRevisions(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeQuestion(c, id, authz.ViewQuestions); !ok {
+		return
+	}
+
+	revisions, err := h.Questions.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// DiffRevisions compares two revisions of a question word by word. to
+// defaults to the latest revision and from to the one before it; revision 1
+// is compared with an empty question.
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	to, ok := queryInt(c, "to", 0)
+	if !ok {
+		return
+	}
+	from, ok := queryInt(c, "from", 0)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeQuestion(c, id, authz.ViewQuestions); !ok {
+		return
+	}
+
+	revisions, err := h.Questions.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if to == 0 && len(revisions) > 0 {
+		to = revisions[0].Revision
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	// Revisions come newest first, numbered from 1
+	revision := func(n int) *models.QuestionRevision {
+		if i := len(revisions) - n; n >= 1 && i >= 0 {
+			return &revisions[i]
+		}
+		return nil
+	}
+	a, b := revision(from), revision(to)
+	if from == 0 {
+		a = &models.QuestionRevision{}
+	}
+	if a == nil || b == nil {
+		c.Error(apperr.NotFound("revision_not_found", "Revision not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"question_id": id,
+		"from":        from,
+		"to":          to,
+		"changes": gin.H{
+			"question":   diff.Words(a.Question, b.Question),
+			"answer":     diff.Words(a.Answer, b.Answer),
+			"context":    diff.Words(a.Context, b.Context),
+			"difficulty": diff.Words(a.Difficulty, b.Difficulty),
+		},
+	})
+}
+
+// RestoreRevision puts an old revision's content back. The restore is
+// itself saved as a new revision, so it can be undone the same way.
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	rev, ok := paramID(c, "rev")
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeQuestion(c, id, authz.EditQuestion); !ok {
+		return
+	}
+
+	q, err := h.Questions.RestoreRevision(c.Request.Context(), id, rev, c.GetInt("user_id"))
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("revision_not_found", "Revision not found"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, q)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"interview-prep/apperr"
+	"interview-prep/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchQuestions ranks questions in the caller's categories, or in every
+// category for admins, against q, which accepts web-search syntax: quoted
+// phrases, "or" and -exclusions.
+func (h *Handler) SearchQuestions(c *gin.Context) {
+	search := models.QuestionSearch{
+		Query:  strings.TrimSpace(c.Query("q")),
+		UserID: c.GetInt("user_id"),
+		Admin:  subject(c).Admin,
+	}
+	if search.Query == "" {
+		c.Error(apperr.BadRequest("query_required", "q is required"))
+		return
+	}
+
+	var ok bool
+	if search.Difficulty, ok = queryDifficulty(c); !ok {
+		return
+	}
+	if search.CategoryID, ok = queryInt(c, "category_id", 0); !ok {
+		return
+	}
+	if search.CreatedBy, ok = queryInt(c, "created_by", 0); !ok {
+		return
+	}
+	if search.Limit, ok = queryInt(c, "limit", defaultSearchLimit); !ok {
+		return
+	}
+	if search.Limit < 1 || search.Limit > maxSearchLimit {
+		c.Error(apperr.BadRequest("invalid_limit", "limit must be between 1 and 100"))
+		return
+	}
+
+	results, err := h.Questions.SearchQuestions(c.Request.Context(), search)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/logging"
+	"interview-prep/models"
+	"interview-prep/review"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStatsBuckets = 12
+	maxStatsBuckets     = 100
+
+	// Categories need this many attempts before they can rank as weakest
+	weakestMinAttempts = 5
+	weakestLimit       = 5
+)
+
+// record adds an event to the caller's activity log. The action it
+// describes has already happened, so a failure is only logged and the
+// request still succeeds.
+func (h *Handler) record(c *gin.Context, e models.StudyEvent) {
+	e.UserID = c.GetInt("user_id")
+	ctx := c.Request.Context()
+	if err := h.Stats.RecordEvent(ctx, &e); err != nil {
+		logging.FromContext(ctx).Error("recording study event", "error", err, "type", e.Type)
+	}
+}
+
+// RecordStudyEvent logs study actions that happen only in the client, which
+// for now is viewing a question. Answers, grades and interviews are logged
+// by the endpoints that handle them.
+func (h *Handler) RecordStudyEvent(c *gin.Context) {
+	var req struct {
+		Type       string `json:"type"`
+		QuestionID int    `json:"question_id"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	if req.Type != models.EventViewed {
+		c.Error(apperr.BadRequest("invalid_type", "type must be viewed"))
+		return
+	}
+
+	q, ok := h.authorizeQuestion(c, req.QuestionID, authz.ViewQuestions)
+	if !ok {
+		return
+	}
+
+	e := models.StudyEvent{
+		UserID:     c.GetInt("user_id"),
+		Type:       req.Type,
+		QuestionID: q.ID,
+		CategoryID: q.CategoryID,
+		Difficulty: q.Difficulty,
+	}
+	if err := h.Stats.RecordEvent(c.Request.Context(), &e); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, e)
+}
+
+// GetMyStats reports the caller's progress: activity totals, streaks,
+// mastery per category and difficulty, accuracy over the last buckets
+// (day, week or month) and the weakest categories.
+func (h *Handler) GetMyStats(c *gin.Context) {
+	bucket := c.DefaultQuery("bucket", models.BucketWeek)
+	switch bucket {
+	case models.BucketDay, models.BucketWeek, models.BucketMonth:
+	default:
+		c.Error(apperr.BadRequest("invalid_bucket", "bucket must be day, week or month"))
+		return
+	}
+	count, ok := queryInt(c, "buckets", defaultStatsBuckets)
+	if !ok {
+		return
+	}
+	if count < 1 || count > maxStatsBuckets {
+		c.Error(apperr.BadRequest("invalid_buckets", "buckets must be between 1 and 100"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+	now := time.Now()
+	var stats models.UserStats
+
+	activity, err := h.Stats.ActivityTotals(ctx, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	stats.Activity = *activity
+
+	streak, err := h.Stats.Streak(ctx, userID, now)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	stats.Streak = *streak
+
+	if stats.Mastery, err = h.Stats.Mastery(ctx, userID, review.MatureIntervalDays); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Fill in the buckets without attempts so the series has no gaps
+	first := models.AddBuckets(models.BucketStart(now, bucket), bucket, 1-count)
+	buckets, err := h.Stats.Accuracy(ctx, userID, bucket, first)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	stats.Accuracy = make([]models.AccuracyBucket, 0, count)
+	for i := range count {
+		start := models.AddBuckets(first, bucket, i)
+		b := models.AccuracyBucket{Start: start}
+		for _, found := range buckets {
+			if found.Start.Equal(start) {
+				b = found
+			}
+		}
+		stats.Accuracy = append(stats.Accuracy, b)
+	}
+
+	if stats.WeakestCategories, err = h.Stats.WeakestCategories(ctx, userID, weakestMinAttempts, weakestLimit); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxQuestionTags     = 20
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// normalizeTags normalizes and de-duplicates tag names, answering 400 if
+// one has nothing usable in it or there are too many. nil stays nil.
+func normalizeTags(c *gin.Context, names []string) ([]string, bool) {
+	if names == nil {
+		return nil, true
+	}
+	tags := []string{}
+	for _, name := range names {
+		tag := models.NormalizeTag(name)
+		if tag == "" {
+			c.Error(apperr.BadRequest("invalid_tag", fmt.Sprintf("Invalid tag %q", name)))
+			return nil, false
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxQuestionTags {
+		c.Error(apperr.BadRequest("too_many_tags", "A question can have at most 20 tags"))
+		return nil, false
+	}
+	sort.Strings(tags)
+	return tags, true
+}
+
+// tagQuery reads the category_id and prefix shared by the tag listings,
+// checking the caller can see the category when one is given.
+func (h *Handler) tagQuery(c *gin.Context) (models.TagQuery, bool) {
+	s := subject(c)
+	q := models.TagQuery{UserID: s.UserID, Prefix: models.NormalizeTag(c.Query("prefix"))}
+	if s.Admin {
+		q.UserID = 0
+	}
+
+	var ok bool
+	if q.CategoryID, ok = queryInt(c, "category_id", 0); !ok {
+		return q, false
+	}
+	if q.CategoryID != 0 {
+		if _, ok := h.authorize(c, q.CategoryID, authz.ViewQuestions); !ok {
+			return q, false
+		}
+	}
+	return q, true
+}
+
+// GetTags lists the tags of every category the caller can read, or of one
+// with category_id, with how many questions use each.
+func (h *Handler) GetTags(c *gin.Context) {
+	q, ok := h.tagQuery(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.Tags.ListTags(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// SuggestTags autocompletes a tag name from its prefix. Names shared by
+// several categories are merged, and the most used come first.
+func (h *Handler) SuggestTags(c *gin.Context) {
+	q, ok := h.tagQuery(c)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", defaultSuggestLimit)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > maxSuggestLimit {
+		c.Error(apperr.BadRequest("invalid_limit", "limit must be between 1 and 50"))
+		return
+	}
+
+	tags, err := h.Tags.ListTags(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	type suggestion struct {
+		Name          string `json:"name"`
+		QuestionCount int    `json:"question_count"`
+	}
+	suggestions := []suggestion{}
+	for _, t := range tags {
+		// ListTags sorts by name, so repeats are adjacent
+		if n := len(suggestions); n > 0 && suggestions[n-1].Name == t.Name {
+			suggestions[n-1].QuestionCount += t.QuestionCount
+			continue
+		}
+		suggestions = append(suggestions, suggestion{t.Name, t.QuestionCount})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].QuestionCount > suggestions[j].QuestionCount
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *Handler) CreateTag(c *gin.Context) {
+	categoryID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	tag := models.Tag{CategoryID: categoryID, Name: models.NormalizeTag(req.Name), CreatedBy: c.GetInt("user_id")}
+	if tag.Name == "" {
+		c.Error(apperr.BadRequest("tag_name_required", "Tag name is required"))
+		return
+	}
+
+	if _, ok := h.authorize(c, categoryID, authz.CreateTag); !ok {
+		return
+	}
+
+	err := h.Tags.CreateTag(c.Request.Context(), &tag)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(apperr.Conflict("tag_exists", "Tag already exists in this category"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// authorizeTag fetches a tag and checks action against its category.
+func (h *Handler) authorizeTag(c *gin.Context, id int, action authz.Action) (*models.Tag, bool) {
+	tag, err := h.Tags.GetTag(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errTagNotFound)
+		return nil, false
+	} else if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	if _, ok := h.authorize(c, tag.CategoryID, action); !ok {
+		return nil, false
+	}
+	return tag, true
+}
+
+// RenameTag renames a tag on every question carrying it.
+func (h *Handler) RenameTag(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !BindJSON(c, &req) {
+		return
+	}
+	name := models.NormalizeTag(req.Name)
+	if name == "" {
+		c.Error(apperr.BadRequest("tag_name_required", "Tag name is required"))
+		return
+	}
+
+	tag, ok := h.authorizeTag(c, id, authz.ManageTags)
+	if !ok {
+		return
+	}
+
+	err := h.Tags.RenameTag(c.Request.Context(), id, name)
+	if errors.Is(err, store.ErrConflict) {
+		c.Error(apperr.Conflict("tag_exists", "Tag already exists in this category"))
+		return
+	} else if errors.Is(err, store.ErrNotFound) {
+		c.Error(errTagNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tag.Name = name
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag deletes a tag and takes it off every question carrying it.
+func (h *Handler) DeleteTag(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTag(c, id, authz.ManageTags); !ok {
+		return
+	}
+
+	err := h.Tags.DeleteTag(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(errTagNotFound)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted"})
+}
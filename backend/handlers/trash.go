@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"interview-prep/authz"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashRetention is how long a deleted question can be restored before it
+// is purged for good.
+const TrashRetention = 30 * 24 * time.Hour
+
+// GetTrash lists the deleted questions the caller could restore, optionally
+// within one category.
+func (h *Handler) GetTrash(c *gin.Context) {
+	categoryID, ok := queryInt(c, "category_id", 0)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	s := subject(c)
+
+	userID := s.UserID
+	if s.Admin {
+		userID = 0
+	}
+	trash, err := h.Questions.ListTrash(ctx, userID, categoryID, time.Now().Add(-TrashRetention))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	roles := map[int]string{}
+	items := []models.TrashedQuestion{}
+	for _, t := range trash {
+		role, seen := roles[t.CategoryID]
+		if !seen {
+			if role, err = h.Permissions.CategoryRole(ctx, t.CategoryID, s.UserID); err != nil {
+				c.Error(err)
+				return
+			}
+			roles[t.CategoryID] = role
+		}
+		if !authz.Can(s, authz.RestoreQuestion, authz.Resource{Role: role, Question: &t.Question}) {
+			continue
+		}
+		t.PurgeAt = t.DeletedAt.Add(TrashRetention)
+		items = append(items, t)
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreQuestion takes a question out of the trash.
+func (h *Handler) RestoreQuestion(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	since := time.Now().Add(-TrashRetention)
+
+	t, err := h.Questions.GetTrashedQuestion(ctx, id, since)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("trashed_question_not_found", "Question not found in trash"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	if _, ok := h.decide(c, t.CategoryID, authz.RestoreQuestion, &t.Question); !ok {
+		return
+	}
+
+	err = h.Questions.RestoreQuestion(ctx, id, since)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Error(apperr.NotFound("trashed_question_not_found", "Question not found in trash"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Question restored", "question": t.Question})
+}
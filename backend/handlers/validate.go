@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"interview-prep/apperr"
+	"interview-prep/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// normalizer is a request that tidies itself up, trimming whitespace and
+// the like, before it is validated.
+type normalizer interface {
+	Normalize()
+}
+
+// BindJSON decodes the request body into v, normalises it and checks its
+// validate tags, answering 400 with the fields at fault when any step fails.
+func BindJSON(c *gin.Context, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		c.Error(apperr.InvalidBody(err))
+		return false
+	}
+	if n, ok := v.(normalizer); ok {
+		n.Normalize()
+	}
+	return Validate(c, v)
+}
+
+// Validate checks v's validate tags, answering 400 with the fields at
+// fault when they fail. Handlers that normalise a request after binding it
+// validate it again.
+func Validate(c *gin.Context, v any) bool {
+	fields, err := validation.Struct(v)
+	if err != nil {
+		c.Error(err)
+		return false
+	}
+	if len(fields) == 0 {
+		return true
+	}
+	// The first field's message doubles as the summary
+	c.Error(apperr.Invalid(fields[0].Message, fields...))
+	return false
+}
@@ -1,47 +1,64 @@
 package helpers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"os"
+	"interview-prep/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// AccessTokenTTL is kept short because access tokens are only checked
+	// against the session table, not re-issued, until they expire.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a session survives without a refresh.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	// Lifetimes of the single-use tokens mailed to users.
+	VerifyEmailTokenTTL   = 48 * time.Hour
+	ResetPasswordTokenTTL = time.Hour
+)
+
 type Claims struct {
-	Email  string `json:"email"`
-	UserID int    `json:"user_id"`
-	Role   string `json:"role"`
+	Email     string `json:"email"`
+	UserID    int    `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func getJWTKey() []byte {
-	key := os.Getenv("JWT_SECRET")
-	if key == "" {
-		key = "your_secret_key" // Fallback for local development
-	}
-	return []byte(key)
+// Tokens signs and checks access tokens with the server's secret.
+type Tokens struct {
+	key []byte
 }
 
-func GenerateToken(email string, userID int, role string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+func NewTokens(secret string) *Tokens {
+	return &Tokens{key: []byte(secret)}
+}
+
+func (t *Tokens) GenerateToken(email string, userID int, role string, sessionID string) (string, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
 	claims := &Claims{
-		Email:  email,
-		UserID: userID,
-		Role:   role,
+		Email:     email,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(getJWTKey())
+	return token.SignedString(t.key)
 }
 
-func ValidateToken(signedToken string) (*Claims, error) {
+func (t *Tokens) ValidateToken(signedToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(signedToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return getJWTKey(), nil
+		return t.key, nil
 	})
 
 	if err != nil {
@@ -57,9 +74,33 @@ func ValidateToken(signedToken string) (*Claims, error) {
 		return nil, errors.New("token expired")
 	}
 
+	if claims.SessionID == "" {
+		return nil, errors.New("token has no session")
+	}
+
 	return claims, nil
 }
 
+// GenerateRefreshToken returns an opaque refresh token and the hash under
+// which it is stored. Only the hash is persisted.
+func GenerateRefreshToken() (token string, hash string) {
+	token = config.GenerateRandomKey()
+	return token, HashToken(token)
+}
+
+// GenerateAccountToken returns a single-use token to mail to a user and the
+// hash under which it is stored.
+func GenerateAccountToken() (token string, hash string) {
+	token = config.GenerateRandomKey()
+	return token, HashToken(token)
+}
+
+// HashToken is the SHA-256 hex digest opaque tokens are stored under.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"interview-prep/models"
+	"io"
+	"strings"
+)
+
+// parseCSV reads a file whose header row names the columns: question and
+// answer are required, context and difficulty optional, others ignored.
+func parseCSV(r io.Reader) ([]record, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	} else if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"question", "answer"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must have a %q column", required)
+		}
+	}
+
+	field := func(fields []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	var records []record
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) && errors.Is(perr.Err, csv.ErrFieldCount) {
+			records = append(records, record{problem: fmt.Sprintf("line %d has %d fields, the header has %d", perr.Line, len(fields), len(header))})
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		records = append(records, record{question: models.Question{
+			Question:   field(fields, "question"),
+			Answer:     field(fields, "answer"),
+			Context:    field(fields, "context"),
+			Difficulty: field(fields, "difficulty"),
+		}})
+	}
+}
@@ -0,0 +1,125 @@
+// Package importer reads questions in bulk from CSV, JSON or Markdown files.
+// Parsing never stops at a bad row: every problem is reported against its
+// row so a whole file can be checked before anything is written.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"interview-prep/models"
+	"interview-prep/validation"
+	"io"
+	"mime"
+	"path"
+	"strings"
+)
+
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "md"
+)
+
+// RowError is a problem with one row. Rows count from 1 in file order:
+// data lines for CSV, array elements for JSON and "##" sections for
+// Markdown.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result is what Parse found: the rows that passed validation and the
+// problems with the ones that didn't.
+type Result struct {
+	Total     int
+	Questions []models.Question
+	Errors    []RowError
+}
+
+// DetectFormat picks the format from an explicit name, falling back to the
+// uploaded file's extension and then the content type.
+func DetectFormat(format, filename, contentType string) (string, error) {
+	if format == "" && filename != "" {
+		format = strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	}
+	if format == "" && contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			format = map[string]string{
+				"text/csv":         FormatCSV,
+				"application/json": FormatJSON,
+				"text/markdown":    FormatMarkdown,
+			}[mediaType]
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatMarkdown, "markdown":
+		return FormatMarkdown, nil
+	case "":
+		return "", errors.New("could not tell the file format; pass format=csv, json or md")
+	}
+	return "", fmt.Errorf("unsupported format %q; use csv, json or md", format)
+}
+
+// record is one row as a parser read it. problem is set when the row
+// couldn't be read into a question at all.
+type record struct {
+	question models.Question
+	problem  string
+}
+
+// Parse reads every row of r. The error is only set when the file as a
+// whole can't be read, such as malformed JSON or a CSV without a header.
+func Parse(format string, r io.Reader) (*Result, error) {
+	var records []record
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = parseCSV(r)
+	case FormatJSON:
+		records, err = parseJSON(r)
+	case FormatMarkdown:
+		records, err = parseMarkdown(r)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Total: len(records), Errors: []RowError{}}
+	for i, rec := range records {
+		if rec.problem != "" {
+			res.Errors = append(res.Errors, RowError{Row: i + 1, Message: rec.problem})
+			continue
+		}
+		q := rec.question
+		if errs := Validate(&q, i+1); len(errs) > 0 {
+			res.Errors = append(res.Errors, errs...)
+			continue
+		}
+		res.Questions = append(res.Questions, q)
+	}
+	return res, nil
+}
+
+// Validate normalises q and checks it against the rules the API applies
+// to a question, reporting each field at fault.
+func Validate(q *models.Question, row int) []RowError {
+	q.Normalize()
+
+	fields, err := validation.Struct(q)
+	if err != nil {
+		return []RowError{{Row: row, Message: err.Error()}}
+	}
+	errs := make([]RowError, len(fields))
+	for i, f := range fields {
+		errs[i] = RowError{Row: row, Field: f.Field, Message: f.Message}
+	}
+	return errs
+}
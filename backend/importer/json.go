@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interview-prep/models"
+	"io"
+)
+
+// parseJSON reads an array of objects with the same fields the questions
+// API accepts.
+func parseJSON(r io.Reader) ([]record, error) {
+	var rows []struct {
+		Question   string `json:"question"`
+		Answer     string `json:"answer"`
+		Context    string `json:"context"`
+		Difficulty string `json:"difficulty"`
+	}
+	err := json.NewDecoder(r).Decode(&rows)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return nil, errors.New("JSON must be an array of question objects")
+	} else if err != nil {
+		return nil, fmt.Errorf("reading JSON: %w", err)
+	}
+
+	records := make([]record, len(rows))
+	for i, row := range rows {
+		records[i].question = models.Question{
+			Question:   row.Question,
+			Answer:     row.Answer,
+			Context:    row.Context,
+			Difficulty: row.Difficulty,
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"bufio"
+	"interview-prep/models"
+	"io"
+	"strings"
+)
+
+// parseMarkdown reads one question per "## " heading; the heading is the
+// question and the body up to the next heading is the answer. Front matter
+// at the top of the file sets the default difficulty and context, and a
+// front matter block right under a heading overrides them for that
+// question:
+//
+//	---
+//	difficulty: Easy
+//	---
+//	## What is a goroutine?
+//	---
+//	difficulty: Medium
+//	---
+//	A function running concurrently, scheduled by the Go runtime.
+//
+// Headings inside fenced code blocks are part of the answer.
+func parseMarkdown(r io.Reader) ([]record, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	defaults := map[string]string{}
+	i := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		defaults, i = frontMatter(lines, 0)
+	}
+
+	var records []record
+	var current *models.Question
+	var body []string
+	inFence := false
+	flush := func() {
+		if current != nil {
+			current.Answer = strings.TrimSpace(strings.Join(body, "\n"))
+			records = append(records, record{question: *current})
+		}
+		current, body = nil, nil
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if inFence || !strings.HasPrefix(line, "## ") {
+			if current != nil {
+				body = append(body, line)
+			}
+			continue
+		}
+
+		flush()
+		current = &models.Question{
+			Question:   strings.TrimSpace(strings.TrimPrefix(line, "## ")),
+			Difficulty: defaults["difficulty"],
+			Context:    defaults["context"],
+		}
+
+		next := i + 1
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) && strings.TrimSpace(lines[next]) == "---" {
+			var meta map[string]string
+			meta, next = frontMatter(lines, next)
+			if d, ok := meta["difficulty"]; ok {
+				current.Difficulty = d
+			}
+			if c, ok := meta["context"]; ok {
+				current.Context = c
+			}
+			i = next - 1
+		}
+	}
+	flush()
+	return records, nil
+}
+
+// frontMatter reads "key: value" lines between the "---" at start and the
+// next "---", returning them and the index of the line after the block.
+// An unterminated block is treated as ordinary text.
+func frontMatter(lines []string, start int) (map[string]string, int) {
+	meta := map[string]string{}
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			return meta, i + 1
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			meta[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return map[string]string{}, start
+}
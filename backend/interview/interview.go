@@ -0,0 +1,179 @@
+// Package interview holds the rules of timed mock interviews: how a session
+// is planned, which question is current, and how the report is computed.
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"interview-prep/models"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultTimeLimitSeconds = 120
+	MinTimeLimitSeconds     = 10
+	MaxTimeLimitSeconds     = 3600
+
+	DefaultQuestionCount = 5
+	MaxQuestionCount     = 50
+
+	MinSelfRating = 1
+	MaxSelfRating = 5
+	// PassingSelfRating is the lowest self-rating that counts as a correct
+	// answer in progress stats.
+	PassingSelfRating = 3
+)
+
+// Plan describes the questions to sample for a new session. DifficultyMix
+// maps a difficulty to how many questions of it to include; without a mix,
+// Count questions of any difficulty are drawn.
+type Plan struct {
+	CategoryIDs      []int          `json:"category_ids"`
+	Count            int            `json:"count"`
+	DifficultyMix    map[string]int `json:"difficulty_mix"`
+	TimeLimitSeconds int            `json:"time_limit_seconds"`
+}
+
+// Normalize fills in defaults and rejects plans that can't be sampled.
+func (p *Plan) Normalize() error {
+	if len(p.CategoryIDs) == 0 {
+		return errors.New("category_ids must list at least one category")
+	}
+
+	if p.TimeLimitSeconds == 0 {
+		p.TimeLimitSeconds = DefaultTimeLimitSeconds
+	}
+	if p.TimeLimitSeconds < MinTimeLimitSeconds || p.TimeLimitSeconds > MaxTimeLimitSeconds {
+		return fmt.Errorf("time_limit_seconds must be between %d and %d", MinTimeLimitSeconds, MaxTimeLimitSeconds)
+	}
+
+	if len(p.DifficultyMix) > 0 {
+		total := 0
+		mix := make(map[string]int, len(p.DifficultyMix))
+		for difficulty, n := range p.DifficultyMix {
+			d, ok := models.NormalizeDifficulty(difficulty)
+			if !ok {
+				return fmt.Errorf("difficulty_mix has unknown difficulty %q", difficulty)
+			}
+			if n < 0 {
+				return fmt.Errorf("difficulty_mix count for %q cannot be negative", difficulty)
+			}
+			mix[d] += n
+			total += n
+		}
+		p.DifficultyMix = mix
+		if p.Count == 0 {
+			p.Count = total
+		} else if p.Count != total {
+			return fmt.Errorf("difficulty_mix adds up to %d but count is %d", total, p.Count)
+		}
+	}
+
+	if p.Count == 0 {
+		p.Count = DefaultQuestionCount
+	}
+	if p.Count < 1 || p.Count > MaxQuestionCount {
+		return fmt.Errorf("count must be between 1 and %d", MaxQuestionCount)
+	}
+	return nil
+}
+
+// Buckets returns how many questions to draw per difficulty, in a stable
+// order. An empty difficulty means any.
+func (p Plan) Buckets() []Bucket {
+	if len(p.DifficultyMix) == 0 {
+		return []Bucket{{Count: p.Count}}
+	}
+
+	buckets := make([]Bucket, 0, len(p.DifficultyMix))
+	for difficulty, n := range p.DifficultyMix {
+		if n > 0 {
+			buckets = append(buckets, Bucket{Difficulty: difficulty, Count: n})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Difficulty < buckets[j].Difficulty })
+	return buckets
+}
+
+type Bucket struct {
+	Difficulty string
+	Count      int
+}
+
+// Current returns the first unanswered question of an in-progress session,
+// or nil once every question has been answered.
+func Current(s *models.InterviewSession) *models.InterviewQuestion {
+	if s.Status != models.InterviewInProgress {
+		return nil
+	}
+	for i := range s.Questions {
+		if s.Questions[i].AnsweredAt == nil {
+			return &s.Questions[i]
+		}
+	}
+	return nil
+}
+
+// Deadline is when the answer to a served question is due.
+func Deadline(s *models.InterviewSession, q *models.InterviewQuestion) time.Time {
+	if q.ServedAt == nil {
+		return time.Time{}
+	}
+	return q.ServedAt.Add(time.Duration(s.TimeLimitSeconds) * time.Second)
+}
+
+// Summarize builds the end-of-session report.
+func Summarize(s *models.InterviewSession) models.InterviewSummary {
+	summary := models.InterviewSummary{
+		TotalQuestions: len(s.Questions),
+		ByDifficulty:   map[string]models.DifficultySummary{},
+	}
+
+	var totalTime, totalRating, rated int
+	ratingSums := map[string]int{}
+	ratingCounts := map[string]int{}
+	for _, q := range s.Questions {
+		d := summary.ByDifficulty[q.Difficulty]
+		d.Total++
+
+		if q.AnsweredAt == nil {
+			summary.Skipped++
+			summary.ByDifficulty[q.Difficulty] = d
+			continue
+		}
+
+		summary.Answered++
+		d.Answered++
+		if q.TimedOut {
+			summary.TimedOut++
+		}
+		if q.TimeTakenMs != nil {
+			totalTime += *q.TimeTakenMs
+		}
+		if q.SelfRating != nil {
+			totalRating += *q.SelfRating
+			rated++
+			ratingSums[q.Difficulty] += *q.SelfRating
+			ratingCounts[q.Difficulty]++
+		}
+		summary.ByDifficulty[q.Difficulty] = d
+	}
+
+	if summary.Answered > 0 {
+		summary.AverageTimeMs = totalTime / summary.Answered
+	}
+	if rated > 0 {
+		summary.AverageRating = float64(totalRating) / float64(rated)
+	}
+	for difficulty, d := range summary.ByDifficulty {
+		if ratingCounts[difficulty] > 0 {
+			d.AverageRating = float64(ratingSums[difficulty]) / float64(ratingCounts[difficulty])
+			summary.ByDifficulty[difficulty] = d
+		}
+	}
+	if s.CompletedAt != nil {
+		summary.TotalDurationMs = s.CompletedAt.Sub(s.CreatedAt).Milliseconds()
+	}
+	return summary
+}
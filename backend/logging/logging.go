@@ -0,0 +1,111 @@
+// Package logging sets up the server's structured logger. Requests carry
+// their own logger, with the request ID and route attached, in their
+// context; code handling a request logs through FromContext so its lines
+// can be told apart from those of other requests.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Formats of the log output.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted replaces the value of anything sensitive.
+const Redacted = "[REDACTED]"
+
+// sensitive names the attributes, headers and query parameters whose values
+// never reach the log, compared case-insensitively.
+var sensitive = map[string]bool{
+	"authorization":    true,
+	"cookie":           true,
+	"set-cookie":       true,
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"token":            true,
+	"refresh_token":    true,
+	"code_verifier":    true,
+	"client_secret":    true,
+	"api_key":          true,
+	"jwt_secret":       true,
+}
+
+// Sensitive reports whether the value named name must be redacted.
+func Sensitive(name string) bool {
+	return sensitive[strings.ToLower(name)]
+}
+
+// New returns a logger writing lines of format to w, leaving out those
+// below level ("debug", "info", "warn" or "error"). Attributes named as
+// secrets are redacted wherever they appear.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q", level)
+	}
+	opts := &slog.HandlerOptions{
+		Level: lvl,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if Sensitive(a.Key) {
+				return slog.String(a.Key, Redacted)
+			}
+			return a
+		},
+	}
+
+	switch format {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger ctx carries, or the default logger when
+// it carries none, as outside a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Headers is h as a group attribute, with sensitive headers redacted.
+func Headers(h http.Header) slog.Attr {
+	return group("headers", h)
+}
+
+// Query is q as a group attribute, with sensitive parameters redacted.
+func Query(q url.Values) slog.Attr {
+	return group("query", q)
+}
+
+func group(name string, values map[string][]string) slog.Attr {
+	attrs := make([]any, 0, len(values))
+	for key, vs := range values {
+		value := strings.Join(vs, ", ")
+		if Sensitive(key) {
+			value = Redacted
+		}
+		attrs = append(attrs, slog.String(key, value))
+	}
+	return slog.Group(name, attrs...)
+}
@@ -0,0 +1,68 @@
+// Package mail sends the emails the backend needs, such as address
+// verification and password reset links. SMTP delivers them for real; Log
+// and Dir keep them local for development and tests.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// format renders m as a plain text RFC 5322 message.
+func format(from string, m Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// Log logs every message to Logger instead of sending it.
+type Log struct {
+	Logger *slog.Logger
+	From   string
+}
+
+func (l Log) Send(ctx context.Context, m Message) error {
+	l.Logger.InfoContext(ctx, "mail not sent; logged instead", "from", l.From, "to", m.To, "subject", m.Subject, "body", m.Body)
+	return nil
+}
+
+// Dir saves every message as an .eml file in Path instead of sending it.
+type Dir struct {
+	Path string
+	From string
+}
+
+var dirSeq atomic.Int64
+
+func (d Dir) Send(ctx context.Context, m Message) error {
+	if err := os.MkdirAll(d.Path, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%d.eml", time.Now().UTC().Format("20060102T150405"), dirSeq.Add(1))
+	return os.WriteFile(filepath.Join(d.Path, name), format(d.From, m), 0o644)
+}
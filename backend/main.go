@@ -1,12 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"interview-prep/config"
+	"interview-prep/controllers"
 	"interview-prep/database"
+	"interview-prep/grading"
 	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/logging"
+	"interview-prep/mail"
 	"interview-prep/middleware"
+	"interview-prep/oidc"
+	"interview-prep/ratelimit"
 	"interview-prep/routes"
+	"interview-prep/store"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"strings"
 
@@ -15,62 +34,256 @@ import (
 )
 
 func main() {
-	db, err := database.Connect()
+	// Flags come before the subcommand, as in `backend -config prod.yaml migrate up`
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
 	if err != nil {
 		log.Fatal(err)
 	}
+	// For libraries that log through the standard logger
+	slog.SetDefault(logger)
+
+	if len(args) > 0 && args[0] == "migrate" {
+		runMigrate(cfg, logger, args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "admin" {
+		runAdmin(cfg, logger, args[1:])
+		return
+	}
+
+	if err := runServer(cfg, logger); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runServer serves the API until it fails or is told to stop. On SIGINT or
+// SIGTERM it fails readiness, waits out the shutdown delay, then stops
+// accepting connections and gives requests in flight until the shutdown
+// timeout to finish before closing the database.
+func runServer(cfg *config.Config, logger *slog.Logger) error {
+	logger.Info("starting", "env", cfg.Env)
+	if cfg.JWTSecret == config.DefaultJWTSecret {
+		logger.Warn("JWT_SECRET is not set; signing tokens with the development default")
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
+	logger.Info("database connected")
 
-	// Run migrations to create tables
-	if err := database.RunMigrations(db); err != nil {
-		log.Printf("Warning: Migration failed: %v", err)
+	// Apply pending migrations; refuses to start if an applied one was edited
+	migrator, err := database.NewMigrator(db, logger)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Up(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
 	}
 
-	h := &handlers.Handler{DB: db}
+	// Stop on SIGINT or SIGTERM; a second signal kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	r := gin.Default()
+	pg := store.NewPostgres(db)
+	go purgeTrash(ctx, pg, logger)
+	health := &handlers.Health{DB: db, Migrator: migrator}
+	h := &handlers.Handler{Categories: pg, Questions: pg, Tags: pg, Permissions: pg, Reviews: pg, Interviews: pg, Stats: pg, Grader: newGrader(cfg.Grader, logger)}
+	tokens := helpers.NewTokens(cfg.JWTSecret)
+	uc := &controllers.UserController{
+		Users:      pg,
+		Admin:      pg,
+		Accounts:   pg,
+		Identities: pg,
+		Sessions:   pg,
+		Mailer:     newMailer(cfg.Mail, logger),
+		Tokens:     tokens,
+		AppURL:     cfg.AppURL,
+		OIDC:       newOIDC(cfg.OIDC, logger),
+	}
+	auth := middleware.AuthMiddleware(pg, tokens)
 
-	// Configure CORS
-	allowedOrigins := []string{"http://localhost:5173", "http://localhost:3000"}
-	if origin := os.Getenv("ALLOWED_ORIGIN"); origin != "" {
-		allowedOrigins = append(allowedOrigins, origin)
+	if cfg.Production() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	// The request log comes first so it sees the final response, panics
+	// included
+	r.Use(middleware.RequestLog(logger), middleware.Recovery())
+	// Rate limits key on the client address, which only the listed proxies
+	// may report in X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
 	}
 
-	log.Printf("Allowed CORS origins: %v", allowedOrigins)
+	logger.Info("CORS configured", "origins", cfg.AllowedOrigins, "origin_suffixes", cfg.AllowedOriginSuffixes)
 
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     allowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{middleware.RequestIDHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
-			return strings.HasSuffix(origin, ".vercel.app")
+			for _, suffix := range cfg.AllowedOriginSuffixes {
+				if strings.HasSuffix(origin, suffix) {
+					return true
+				}
+			}
+			return false
 		},
 	}))
 
+	// Handlers report failures with c.Error; this answers them all as
+	// problem details
+	r.Use(middleware.Errors())
+	r.NoRoute(middleware.NoRoute)
+
+	limits := rateLimits(ratelimit.NewMemory())
+
+	routes.SetupHealthRoutes(r, health)
+
 	// Setup Auth Routes
-	routes.SetupRoutes(r, db)
+	routes.SetupRoutes(r, uc, auth, limits)
 
 	// Setup API Routes
-	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
-	{
-		api.GET("/categories", h.GetCategories)
-		api.POST("/categories", h.CreateCategory)
-		api.DELETE("/categories/:id", h.DeleteCategory)
-		api.POST("/categories/:id/request-access", h.RequestAccess)
-		api.GET("/categories/:id/requests", h.GetRequests)
-		api.POST("/categories/:id/requests/:requestId/respond", h.RespondToRequest)
-
-		api.GET("/questions", h.GetQuestions)
-		api.POST("/questions", h.CreateQuestion)
-		api.PUT("/questions/:id", h.UpdateQuestion)
-		api.DELETE("/questions/:id", h.DeleteQuestion)
-	}
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
-	}
-	r.Run(":" + port)
+	routes.SetupAPIRoutes(r, h, auth, limits)
+
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           r,
+		ReadHeaderTimeout: time.Duration(cfg.ReadTimeout),
+		ReadTimeout:       time.Duration(cfg.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.IdleTimeout),
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	health.Drain()
+	if delay := time.Duration(cfg.ShutdownDelay); delay > 0 {
+		logger.Info("shutting down after the delay; readiness now fails", "delay", delay.String())
+		time.Sleep(delay)
+	}
+	logger.Info("shutting down; waiting for requests in flight", "timeout", time.Duration(cfg.ShutdownTimeout).String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	logger.Info("server stopped")
+	return nil
+}
+
+// rateLimits throttles each route group. Logins are the tightest: every
+// one costs a bcrypt comparison, and repeated failures for an account lock
+// that account out from the address they come from, for longer each time.
+func rateLimits(store ratelimit.Store) routes.Limits {
+	return routes.Limits{
+		Login: []gin.HandlerFunc{
+			ratelimit.Middleware(store, "login-ip", ratelimit.PerMinute(20), ratelimit.ClientIP),
+			ratelimit.Middleware(store, "login-account", ratelimit.PerMinute(10), ratelimit.BodyField("email")),
+			ratelimit.Lockout{
+				Store:     store,
+				Name:      "login-failures",
+				Key:       ratelimit.Both(ratelimit.ClientIP, ratelimit.BodyField("email")),
+				Threshold: 5,
+				Window:    15 * time.Minute,
+				Base:      time.Minute,
+				Max:       time.Hour,
+			}.Middleware(),
+		},
+		Account: []gin.HandlerFunc{
+			ratelimit.Middleware(store, "account-ip", ratelimit.PerMinute(30), ratelimit.ClientIP),
+			ratelimit.Middleware(store, "account-email", ratelimit.PerMinute(5), ratelimit.BodyField("email")),
+		},
+		API: []gin.HandlerFunc{
+			ratelimit.Middleware(store, "api", ratelimit.Limit{Burst: 120, Every: 250 * time.Millisecond}, ratelimit.User),
+		},
+	}
+}
+
+// newMailer sends through the SMTP server when one is set. Otherwise
+// emails are saved under the mail directory, or printed to the log when
+// that isn't set either.
+func newMailer(cfg config.Mail, logger *slog.Logger) mail.Mailer {
+	if cfg.SMTPHost != "" {
+		logger.Info("sending mail through SMTP", "host", cfg.SMTPHost, "port", cfg.SMTPPort)
+		return mail.SMTP{Host: cfg.SMTPHost, Port: cfg.SMTPPort, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.From}
+	}
+	if cfg.Dir != "" {
+		logger.Info("saving outgoing mail to a directory", "dir", cfg.Dir)
+		return mail.Dir{Path: cfg.Dir, From: cfg.From}
+	}
+	logger.Warn("SMTP_HOST is not set; outgoing mail is logged instead of sent")
+	return mail.Log{Logger: logger, From: cfg.From}
+}
+
+// newOIDC offers sign-in through the OpenID Connect provider when an
+// issuer is set.
+func newOIDC(cfg config.OIDC, logger *slog.Logger) *oidc.Provider {
+	if cfg.Issuer == "" {
+		return nil
+	}
+	logger.Info("single sign-on enabled", "issuer", cfg.Issuer)
+	return &oidc.Provider{
+		Name:         cfg.Name,
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	}
+}
+
+// newGrader grades answers with the model at the grader URL when one is
+// set, falling back to the rule-based grader when it fails.
+func newGrader(cfg config.Grader, logger *slog.Logger) grading.Grader {
+	if cfg.URL == "" {
+		return grading.Rules{}
+	}
+	llm := grading.NewHTTP(cfg.URL, cfg.Model, cfg.APIKey, time.Duration(cfg.Timeout))
+	logger.Info("grading answers with a model", "grader", llm.Name(), "url", cfg.URL)
+	return grading.Chain{llm, grading.Rules{}}
+}
+
+// purgeTrash deletes questions that have been in the trash for longer than
+// handlers.TrashRetention, once at startup and then every hour until ctx
+// is done.
+func purgeTrash(ctx context.Context, questions store.QuestionStore, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		n, err := questions.PurgeTrash(ctx, time.Now().Add(-handlers.TrashRetention))
+		if err != nil && ctx.Err() == nil {
+			logger.Error("purging trash failed", "error", err)
+		} else if n > 0 {
+			logger.Info("purged questions from the trash", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
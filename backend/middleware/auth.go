@@ -1,26 +1,45 @@
 package middleware
 
 import (
+	"errors"
+	"interview-prep/apperr"
 	"interview-prep/helpers"
-	"net/http"
+	"interview-prep/store"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(sessions store.SessionStore, tokens *helpers.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			c.Error(apperr.Unauthorized("unauthenticated", "Authorization header is required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := helpers.ValidateToken(tokenString)
+		claims, err := tokens.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Error(apperr.Unauthorized("invalid_token", "Invalid or expired token"))
+			c.Abort()
+			return
+		}
+
+		// Tokens outlive logout unless the session is checked on each request
+		active, err := sessions.SessionActive(c.Request.Context(), claims.SessionID)
+		if errors.Is(err, store.ErrSuspended) {
+			c.Error(apperr.Forbidden("account_suspended", "This account is suspended"))
+			c.Abort()
+			return
+		} else if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !active {
+			c.Error(apperr.Unauthorized("session_revoked", "Session has been revoked"))
 			c.Abort()
 			return
 		}
@@ -28,6 +47,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("email", claims.Email)
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		AddLogAttrs(c, "user_id", claims.UserID)
 		c.Next()
 	}
 }
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"interview-prep/apperr"
+	"interview-prep/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors answers requests whose handlers failed with c.Error as problem
+// details. It must come before the middleware whose errors it answers,
+// such as authentication and rate limits.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		err := apperr.From(last.Err)
+		// Causes are kept from the client but the operator needs them
+		if err.Err != nil && err.Status() >= 500 {
+			logging.FromContext(c.Request.Context()).Error("request failed", "code", err.Code, "error", err.Err)
+		}
+		writeProblem(c, err)
+	}
+}
+
+// NoRoute answers requests for paths no route matches.
+func NoRoute(c *gin.Context) {
+	c.Error(apperr.NotFound("route_not_found", "No such endpoint"))
+}
+
+// writeProblem answers with err as problem details.
+func writeProblem(c *gin.Context, err *apperr.Error) {
+	c.Header("Content-Type", apperr.ContentType)
+	c.AbortWithStatusJSON(err.Status(), err.Problem(c.Request.URL.Path))
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"interview-prep/apperr"
+	"interview-prep/logging"
+	"io"
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID that ties a request to its log lines.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the IDs taken from clients and proxies to ones
+// that are safe to echo and log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// quietPaths are probed often enough that their successes are logged at
+// debug level only.
+var quietPaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// RequestLog gives each request an ID, taken from X-Request-ID when the
+// caller sent a valid one, and echoes it in the response. Handlers find a
+// logger with the ID, method and route attached in the request's context;
+// once the request is done it logs its status and latency. It must come
+// first, so it sees the final response.
+func RequestLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Set("request_id", id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		l := logger.With("request_id", id, "method", c.Request.Method, "route", route)
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), l))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		case quietPaths[c.Request.URL.Path]:
+			level = slog.LevelDebug
+		}
+		// The handlers may have added to the logger, as the auth check
+		// adds the user
+		l = logging.FromContext(c.Request.Context())
+		attrs := []slog.Attr{
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if last := c.Errors.Last(); last != nil {
+			attrs = append(attrs, slog.String("error_code", apperr.From(last.Err).Code))
+		}
+		if c.Request.URL.RawQuery != "" {
+			attrs = append(attrs, logging.Query(c.Request.URL.Query()))
+		}
+		if l.Enabled(c.Request.Context(), slog.LevelDebug) {
+			attrs = append(attrs, logging.Headers(c.Request.Header))
+		}
+		l.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AddLogAttrs attaches args, as in slog.Logger.With, to the logger of the
+// request, for the lines logged after it.
+func AddLogAttrs(c *gin.Context, args ...any) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With(args...)))
+}
+
+// Recovery answers requests whose handler panicked with a 500, logging the
+// panic and its stack with the request.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("panic", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		writeProblem(c, apperr.Wrap(fmt.Errorf("panic: %v", recovered)))
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"interview-prep/config"
+	"interview-prep/database"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: backend migrate <command>
+
+commands:
+  up        apply all pending migrations
+  down [N]  revert the last N applied migrations (default 1)
+  status    list migrations and whether they are applied
+  to N      migrate up or down to version N (0 reverts everything)
+  schema    print the combined up migrations (regenerates schema.sql)`
+
+// runMigrate implements the `migrate` subcommand.
+func runMigrate(cfg *config.Config, logger *slog.Logger, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up", "down", "to", "status", "schema":
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if args[0] == "schema" {
+		schema, err := database.Schema()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(schema)
+		return
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := database.NewMigrator(db, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid step count %q", args[1])
+			}
+		}
+		err = m.Down(steps)
+	case "to":
+		if len(args) < 2 {
+			log.Fatal("migrate to requires a version")
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			log.Fatalf("invalid version %q", args[1])
+		}
+		err = m.To(version)
+	case "status":
+		err = printMigrationStatus(m)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printMigrationStatus(m *database.Migrator) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.Applied {
+			applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, applied)
+	}
+	return m.Verify()
+}
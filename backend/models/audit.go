@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Audited actions on user accounts.
+const (
+	AuditAdminCreated        = "admin_created"
+	AuditRoleChanged         = "role_changed"
+	AuditUserLocked          = "user_locked"
+	AuditUserUnlocked        = "user_unlocked"
+	AuditPasswordResetForced = "password_reset_forced"
+	AuditUserUpdated         = "user_updated"
+	AuditUserSuspended       = "user_suspended"
+	AuditUserUnsuspended     = "user_unsuspended"
+	AuditUserDeleted         = "user_deleted"
+)
+
+// AuditEntry records an administrative change to a user account. ActorID
+// is nil for changes made from the command line.
+type AuditEntry struct {
+	ID           int            `json:"id"`
+	ActorID      *int           `json:"actor_id"`
+	Action       string         `json:"action"`
+	TargetUserID *int           `json:"target_user_id"`
+	Details      map[string]any `json:"details"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+func (e AuditEntry) SortValue(key string) string {
+	return SortTime(e.CreatedAt)
+}
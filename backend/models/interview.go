@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	InterviewInProgress = "IN_PROGRESS"
+	InterviewCompleted  = "COMPLETED"
+)
+
+type InterviewSession struct {
+	ID               int                 `json:"id"`
+	UserID           int                 `json:"user_id"`
+	Status           string              `json:"status"`
+	TimeLimitSeconds int                 `json:"time_limit_seconds"`
+	CreatedAt        time.Time           `json:"created_at"`
+	CompletedAt      *time.Time          `json:"completed_at"`
+	Questions        []InterviewQuestion `json:"questions,omitempty"`
+}
+
+type InterviewQuestion struct {
+	ID          int        `json:"id"`
+	SessionID   int        `json:"session_id"`
+	Position    int        `json:"position"`
+	QuestionID  int        `json:"question_id"`
+	CategoryID  int        `json:"category_id"`
+	Question    string     `json:"question"`
+	Answer      string     `json:"answer,omitempty"`
+	Difficulty  string     `json:"difficulty"`
+	ServedAt    *time.Time `json:"served_at"`
+	AnsweredAt  *time.Time `json:"answered_at"`
+	Response    string     `json:"response"`
+	TimeTakenMs *int       `json:"time_taken_ms"`
+	SelfRating  *int       `json:"self_rating"`
+	TimedOut    bool       `json:"timed_out"`
+}
+
+type InterviewSummary struct {
+	TotalQuestions  int                          `json:"total_questions"`
+	Answered        int                          `json:"answered"`
+	Skipped         int                          `json:"skipped"`
+	TimedOut        int                          `json:"timed_out"`
+	AverageTimeMs   int                          `json:"average_time_ms"`
+	AverageRating   float64                      `json:"average_rating"`
+	TotalDurationMs int64                        `json:"total_duration_ms"`
+	ByDifficulty    map[string]DifficultySummary `json:"by_difficulty"`
+}
+
+type DifficultySummary struct {
+	Total         int     `json:"total"`
+	Answered      int     `json:"answered"`
+	AverageRating float64 `json:"average_rating"`
+}
@@ -1,12 +1,71 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Category roles, weakest first. The owner is the category's creator; the
+// other roles belong to approved members.
+const (
+	RoleViewer      = "viewer"
+	RoleContributor = "contributor"
+	RoleEditor      = "editor"
+	RoleOwner       = "owner"
+)
+
+// CategoryRoles lists every role, weakest first.
+var CategoryRoles = []string{RoleViewer, RoleContributor, RoleEditor, RoleOwner}
+
+// RoleAtLeast reports whether role includes everything min allows. The
+// empty role, for users with no access, includes nothing.
+func RoleAtLeast(role, min string) bool {
+	return slices.Index(CategoryRoles, role) >= slices.Index(CategoryRoles, min)
+}
+
+// Question difficulties, easiest first.
+const (
+	DifficultyEasy   = "Easy"
+	DifficultyMedium = "Medium"
+	DifficultyHard   = "Hard"
+
+	// DefaultDifficulty is given to questions created without one.
+	DefaultDifficulty = DifficultyMedium
+)
+
+// Difficulties lists every difficulty, easiest first.
+var Difficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+// NormalizeDifficulty returns the difficulty d names, ignoring case, and
+// whether it names one.
+func NormalizeDifficulty(d string) (string, bool) {
+	for _, known := range Difficulties {
+		if strings.EqualFold(d, known) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// Longest accepted text, in characters. The validate tags below repeat
+// them.
+const (
+	MaxCategoryNameLength = 100
+	MaxQuestionLength     = 1000
+	MaxAnswerLength       = 10000
+	MaxContextLength      = 2000
+)
 
 type Category struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	UserID        int       `json:"user_id"`
-	CreatorName   string    `json:"creator_name"`
+	ID          int    `json:"id"`
+	Name        string `json:"name" validate:"required,max=100"`
+	UserID      int    `json:"user_id"`
+	CreatorName string `json:"creator_name"`
+	// Role is the requesting user's role, empty without access.
+	// HasPermission is kept for clients that only need to know whether they
+	// can add questions, i.e. are at least a contributor.
+	Role          string    `json:"role"`
 	HasPermission bool      `json:"has_permission"`
 	RequestStatus string    `json:"request_status"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -15,10 +74,146 @@ type Category struct {
 type Question struct {
 	ID         int       `json:"id"`
 	CategoryID int       `json:"category_id"`
-	Question   string    `json:"question"`
-	Answer     string    `json:"answer"`
-	Context    string    `json:"context"`
-	Difficulty string    `json:"difficulty"`
+	Question   string    `json:"question" validate:"required,max=1000"`
+	Answer     string    `json:"answer" validate:"max=10000"`
+	Context    string    `json:"context" validate:"max=2000"`
+	Difficulty string    `json:"difficulty" validate:"oneof=Easy Medium Hard"`
+	Tags       []string  `json:"tags"` // sorted; on update, nil leaves them unchanged
+	CreatedBy  int       `json:"created_by"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// Normalize trims the name and collapses the runs of whitespace inside it,
+// so names differing only in spacing collide.
+func (c *Category) Normalize() {
+	c.Name = strings.Join(strings.Fields(c.Name), " ")
+}
+
+// Normalize trims q's text and spells its difficulty the canonical way,
+// defaulting to DefaultDifficulty. Difficulties it doesn't know are left
+// for validation to reject.
+func (q *Question) Normalize() {
+	q.Question = strings.TrimSpace(q.Question)
+	q.Answer = strings.TrimSpace(q.Answer)
+	q.Context = strings.TrimSpace(q.Context)
+	q.Difficulty = strings.TrimSpace(q.Difficulty)
+	if q.Difficulty == "" {
+		q.Difficulty = DefaultDifficulty
+	} else if d, ok := NormalizeDifficulty(q.Difficulty); ok {
+		q.Difficulty = d
+	}
+}
+
+// QuestionPatch is a partial update of a question; nil fields are left
+// unchanged.
+type QuestionPatch struct {
+	Question   *string  `json:"question"`
+	Answer     *string  `json:"answer"`
+	Context    *string  `json:"context"`
+	Difficulty *string  `json:"difficulty"`
+	Tags       []string `json:"tags"`
+}
+
+// Apply copies the fields p sets onto q. Tags are normalised separately,
+// so nil Tags are copied too and leave the question's tags unchanged.
+func (p QuestionPatch) Apply(q *Question) {
+	if p.Question != nil {
+		q.Question = *p.Question
+	}
+	if p.Answer != nil {
+		q.Answer = *p.Answer
+	}
+	if p.Context != nil {
+		q.Context = *p.Context
+	}
+	if p.Difficulty != nil {
+		q.Difficulty = *p.Difficulty
+	}
+	q.Tags = p.Tags
+}
+
+// QuestionRevision is an immutable snapshot of a question. Revision 1 is
+// the question as created; every edit or restore adds the next one.
+type QuestionRevision struct {
+	QuestionID   int       `json:"question_id"`
+	Revision     int       `json:"revision"`
+	Question     string    `json:"question"`
+	Answer       string    `json:"answer"`
+	Context      string    `json:"context"`
+	Difficulty   string    `json:"difficulty"`
+	EditedBy     int       `json:"edited_by"`
+	EditorName   string    `json:"editor_name"`
+	RestoredFrom int       `json:"restored_from,omitempty"` // set when this revision restored an older one
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TrashedQuestion is a deleted question that can still be restored.
+type TrashedQuestion struct {
+	Question
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy int       `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"` // when it stops being restorable
+}
+
+type AccessRequest struct {
+	ID         int               `json:"id"`
+	CategoryID int               `json:"category_id"`
+	UserID     int               `json:"user_id"`
+	User       AccessRequestUser `json:"user"`
+	Status     string            `json:"status"`
+	Role       string            `json:"role"` // granted once APPROVED
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+type AccessRequestUser struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+type ReviewState struct {
+	UserID         int       `json:"user_id"`
+	QuestionID     int       `json:"question_id"`
+	EaseFactor     float64   `json:"ease_factor"`
+	IntervalDays   int       `json:"interval_days"`
+	Repetitions    int       `json:"repetitions"`
+	Lapses         int       `json:"lapses"`
+	LastGrade      int       `json:"last_grade"`
+	DueAt          time.Time `json:"due_at"`
+	LastReviewedAt time.Time `json:"last_reviewed_at"`
+}
+
+// DueReview is a question waiting to be studied. Review is nil for
+// questions the user has never reviewed.
+type DueReview struct {
+	Question Question     `json:"question"`
+	Review   *ReviewState `json:"review"`
+}
+
+// QuestionSearch filters a full-text search over questions. Zero values
+// leave a filter unset.
+type QuestionSearch struct {
+	Query      string
+	UserID     int
+	Admin      bool // search every category, not only the user's
+	CategoryID int
+	Difficulty string
+	CreatedBy  int
+	Limit      int
+}
+
+// SearchResult is a matching question with its rank and highlighted
+// fragments. The fragments are HTML: the text is escaped and matches are
+// wrapped in <mark></mark>, so they can be rendered as they are.
+type SearchResult struct {
+	Question   Question        `json:"question"`
+	Rank       float64         `json:"rank"`
+	Highlights SearchHighlight `json:"highlights"`
+}
+
+type SearchHighlight struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Context  string `json:"context"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Sort keys accepted by the list endpoints.
+const (
+	SortCreatedAt  = "created_at"
+	SortUpdatedAt  = "updated_at"
+	SortDifficulty = "difficulty"
+	SortName       = "name"
+)
+
+// ListQuery asks a store for one page of a list. Rows are ordered by Sort
+// with the id as tie-breaker, both in the same direction. Stores return up
+// to Limit+1 rows so the caller can tell whether another page follows.
+type ListQuery struct {
+	Sort   string
+	Desc   bool
+	Limit  int
+	After  *Cursor
+	UserID int  // the caller, for permission annotations and filters
+	Admin  bool // the caller is an admin, who sees every category
+
+	CategoryID    int
+	Difficulty    string
+	CreatedBy     int
+	HasPermission *bool
+	Tags          []string // questions carrying every one of these
+	TargetUserID  int      // audit entries about this user
+	Search        string   // users whose name or email contains this
+	Role          string
+	Status        string // one of the User* account states
+}
+
+// Cursor marks the last row of a page: its sort value and id. Value is the
+// row's SortValue for the query's sort key.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// Page is a list response. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// SortTime formats a timestamp for a cursor. Microsecond precision matches
+// Postgres, and the fixed width keeps values ordered as strings.
+func SortTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000")
+}
+
+func (q Question) SortValue(key string) string {
+	switch key {
+	case SortUpdatedAt:
+		return SortTime(q.UpdatedAt)
+	case SortDifficulty:
+		return q.Difficulty
+	default:
+		return SortTime(q.CreatedAt)
+	}
+}
+
+func (c Category) SortValue(key string) string {
+	if key == SortName {
+		return c.Name
+	}
+	return SortTime(c.CreatedAt)
+}
+
+func (u User) SortValue(key string) string {
+	if key == SortName {
+		return u.FirstName + " " + u.LastName
+	}
+	return SortTime(u.CreatedAt)
+}
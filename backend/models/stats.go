@@ -0,0 +1,112 @@
+package models
+
+import "time"
+
+// Study event types.
+const (
+	EventViewed             = "viewed"
+	EventAnswered           = "answered"
+	EventGraded             = "graded"
+	EventInterviewCompleted = "interview_completed"
+)
+
+// StudyEvent is one entry in a user's activity log. Grade is the review
+// grade or interview self-rating, and Correct whether that counts as
+// knowing the answer; both are nil for events without one.
+type StudyEvent struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Type        string    `json:"type"`
+	QuestionID  int       `json:"question_id,omitempty"`
+	CategoryID  int       `json:"category_id,omitempty"`
+	InterviewID int       `json:"interview_id,omitempty"`
+	Difficulty  string    `json:"difficulty,omitempty"`
+	Grade       *int      `json:"grade,omitempty"`
+	Correct     *bool     `json:"correct,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserStats is the progress report behind GET /api/me/stats.
+type UserStats struct {
+	Activity          ActivityTotals     `json:"activity"`
+	Streak            Streak             `json:"streak"`
+	Mastery           []MasteryStats     `json:"mastery"`
+	Accuracy          []AccuracyBucket   `json:"accuracy"`
+	WeakestCategories []CategoryAccuracy `json:"weakest_categories"`
+}
+
+type ActivityTotals struct {
+	Viewed              int `json:"viewed"`
+	Answered            int `json:"answered"`
+	Graded              int `json:"graded"`
+	InterviewsCompleted int `json:"interviews_completed"`
+	ActiveDays          int `json:"active_days"`
+}
+
+// Streak counts consecutive days with any activity. The current streak
+// survives until a full day passes without any.
+type Streak struct {
+	Current    int        `json:"current"`
+	Longest    int        `json:"longest"`
+	LastActive *time.Time `json:"last_active"`
+}
+
+// MasteryStats counts the questions of one category and difficulty the user
+// has reviewed, and how many of those are mastered.
+type MasteryStats struct {
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Difficulty   string `json:"difficulty"`
+	Reviewed     int    `json:"reviewed"`
+	Mastered     int    `json:"mastered"`
+}
+
+// AccuracyBucket is the share of correct attempts in the bucket that starts
+// at Start.
+type AccuracyBucket struct {
+	Start    time.Time `json:"start"`
+	Attempts int       `json:"attempts"`
+	Correct  int       `json:"correct"`
+	Accuracy float64   `json:"accuracy"`
+}
+
+type CategoryAccuracy struct {
+	CategoryID   int     `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Attempts     int     `json:"attempts"`
+	Correct      int     `json:"correct"`
+	Accuracy     float64 `json:"accuracy"`
+}
+
+// Accuracy bucket sizes. Weeks start on Monday.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// BucketStart truncates t, in UTC, to the start of its bucket.
+func BucketStart(t time.Time, bucket string) time.Time {
+	y, m, d := t.UTC().Date()
+	switch bucket {
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case BucketWeek:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// AddBuckets moves a bucket start n buckets forward, or back for negative n.
+func AddBuckets(start time.Time, bucket string, n int) time.Time {
+	switch bucket {
+	case BucketMonth:
+		return start.AddDate(0, n, 0)
+	case BucketWeek:
+		return start.AddDate(0, 0, 7*n)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
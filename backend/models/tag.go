@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxTagLength is the longest tag name, in characters.
+const MaxTagLength = 50
+
+// Tag labels questions within one category. Names are unique per category,
+// so the same name in two categories is two tags.
+type Tag struct {
+	ID            int       `json:"id"`
+	CategoryID    int       `json:"category_id"`
+	Name          string    `json:"name"`
+	QuestionCount int       `json:"question_count"`
+	CreatedBy     int       `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TagQuery filters a tag listing. Zero values leave a filter unset.
+type TagQuery struct {
+	UserID     int // only categories this user has a role on
+	CategoryID int
+	Prefix     string
+}
+
+// NormalizeTag turns a tag name into its stored form: lower case, with runs
+// of spaces and underscores as single hyphens and anything other than
+// letters, digits, "+", "#" and "." dropped. It returns "" when nothing
+// usable is left, and truncates to MaxTagLength.
+func NormalizeTag(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.':
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		case r == '-' || r == '_' || unicode.IsSpace(r):
+			hyphen = true
+		}
+	}
+
+	tag := []rune(b.String())
+	if len(tag) > MaxTagLength {
+		tag = []rune(strings.TrimRight(string(tag[:MaxTagLength]), "-"))
+	}
+	return string(tag)
+}
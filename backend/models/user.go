@@ -3,13 +3,118 @@ package models
 import "time"
 
 type User struct {
-    ID        int       `json:"id"`
-    FirstName string    `json:"first_name" validate:"required,min=2,max=100"`
-    LastName  string    `json:"last_name" validate:"required,min=2,max=100"`
-    Email     string    `json:"email" validate:"required,email"`
-    Password  string    `json:"password" validate:"required,min=6"`
-    Phone     string    `json:"phone" validate:"required"`
-    Role      string    `json:"role" validate:"required,eq=ADMIN|eq=USER"`
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	// Password is the bcrypt hash, which is never sent to clients.
+	Password        string     `json:"-"`
+	Phone           string     `json:"phone"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Locked users can't sign in; PasswordResetRequired blocks sign-in
+	// until a new password is set through a reset link.
+	LockedAt              *time.Time `json:"locked_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	// Suspended users can't sign in or use their sessions until
+	// SuspendedUntil, or until an admin lifts an open-ended suspension.
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspendedUntil   *time.Time `json:"suspended_until"`
+	SuspensionReason string     `json:"suspension_reason"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// SignupRequest is the body of a signup. It is the only place a new
+// password is read alongside the profile, and is never written back.
+type SignupRequest struct {
+	FirstName string `json:"first_name" validate:"required,min=2,max=100"`
+	LastName  string `json:"last_name" validate:"required,min=2,max=100"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=6"`
+	Phone     string `json:"phone" validate:"required"`
+}
+
+// User returns the account r asks for, with the password still in the
+// clear.
+func (r *SignupRequest) User() User {
+	return User{FirstName: r.FirstName, LastName: r.LastName, Email: r.Email, Password: r.Password, Phone: r.Phone}
+}
+
+// Suspended reports whether u is suspended at now.
+func (u *User) Suspended(now time.Time) bool {
+	return u.SuspendedAt != nil && (u.SuspendedUntil == nil || u.SuspendedUntil.After(now))
+}
+
+// Account states the user list can be filtered by.
+const (
+	UserActive     = "active"
+	UserLocked     = "locked"
+	UserSuspended  = "suspended"
+	UserUnverified = "unverified"
+)
+
+// UserSummary is what an admin sees about a user before changing or
+// deleting their account.
+type UserSummary struct {
+	User            User             `json:"user"`
+	OwnedCategories []OwnedCategory  `json:"owned_categories"`
+	Permissions     []UserPermission `json:"permissions"`
+}
+
+type OwnedCategory struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	QuestionCount int       `json:"question_count"`
+	MemberCount   int       `json:"member_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UserPermission is a user's access request or membership on a category
+// owned by someone else.
+type UserPermission struct {
+	CategoryID   int       `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Status       string    `json:"status"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// User roles. Admins act as owner of every category.
+const (
+	RoleAdmin = "ADMIN"
+	RoleUser  = "USER"
+)
+
+// Purposes of the single-use tokens mailed to users.
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    int        `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// OIDCLogin is a sign-in through the identity provider waiting for it to
+// redirect back.
+type OIDCLogin struct {
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// ExternalIdentity is who the identity provider says signed in.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
 }
@@ -0,0 +1,219 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"interview-prep/oidc"
+	"interview-prep/oidc/oidctest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signIn runs the flow against srv, with the provider issuing an ID token
+// with claims, and returns what Exchange makes of it.
+func signIn(t *testing.T, srv *oidctest.Server, p *oidc.Provider, claims jwt.MapClaims) (*oidc.Claims, error) {
+	t.Helper()
+	ctx := context.Background()
+	verifier, nonce := oidc.NewVerifier(), oidc.NewState()
+	authURL, err := p.AuthCodeURL(ctx, oidc.NewState(), nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := srv.Authorize(authURL, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Exchange(ctx, code, verifier, nonce)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+	p := srv.Provider()
+
+	authURL, err := p.AuthCodeURL(context.Background(), "the-state", "the-nonce", "the-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	sum := sha256.Sum256([]byte("the-verifier"))
+	want := map[string]string{
+		"response_type": "code",
+		"client_id":     "client-1",
+		"redirect_uri":  p.RedirectURL,
+		"scope":         "openid email profile",
+		"state":         "the-state",
+		"nonce":         "the-nonce",
+		// The verifier itself stays with the app
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+	}
+	if !strings.HasPrefix(authURL, srv.URL+"/authorize?") {
+		t.Errorf("authorization URL = %s", authURL)
+	}
+	for key, value := range want {
+		if got := q.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+
+	claims, err := signIn(t, srv, srv.Provider(), srv.Claims("user-1", "ann@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := oidc.Claims{Subject: "user-1", Email: "ann@example.com", EmailVerified: true, GivenName: "Test", FamilyName: "User"}
+	if *claims != want {
+		t.Errorf("claims = %+v, want %+v", *claims, want)
+	}
+}
+
+func TestExchangeEmailVerified(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		value any
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"false", false},
+		{nil, false},
+	} {
+		c := srv.Claims("user-1", "ann@example.com")
+		c["email_verified"] = tt.value
+		claims, err := signIn(t, srv, srv.Provider(), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.EmailVerified != tt.want {
+			t.Errorf("email_verified %v read as %v", tt.value, claims.EmailVerified)
+		}
+	}
+}
+
+func TestExchangeRejectsBadTokens(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hour := time.Hour
+
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+		err    string
+	}{
+		{"nonce mismatch", func(c jwt.MapClaims) { c["nonce"] = "someone-elses-nonce" }, "nonce mismatch"},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-hour).Unix() }, "expired"},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }, "exp"},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(hour).Unix() }, "used before issued"},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "client-2" }, "audience"},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, "issuer"},
+		{"issued to another party", func(c jwt.MapClaims) { c["aud"] = []string{"client-1", "client-2"}; c["azp"] = "client-2" }, "another party"},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }, "no subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := srv.Claims("user-1", "ann@example.com")
+			tt.change(c)
+			if _, err := signIn(t, srv, srv.Provider(), c); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("signed by another key", func(t *testing.T) {
+		p := srv.Provider()
+		ctx := context.Background()
+		c := srv.Claims("user-1", "ann@example.com")
+		c["nonce"] = "n"
+		if _, err := p.Verify(ctx, oidctest.Sign(otherKey, c), "n"); err == nil {
+			t.Error("accepted a token signed by another key")
+		}
+		if _, err := p.Verify(ctx, oidctest.Sign(srv.Key, c), "n"); err != nil {
+			t.Errorf("rejected a good token: %v", err)
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		c := srv.Claims("user-1", "ann@example.com")
+		c["nonce"] = "n"
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Provider().Verify(context.Background(), token, "n"); err == nil {
+			t.Error("accepted an unsigned token")
+		}
+	})
+}
+
+func TestExchangeFailures(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+	p := srv.Provider()
+	ctx := context.Background()
+
+	if _, err := p.Exchange(ctx, "no-such-code", "verifier", "nonce"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("unknown code: err = %v", err)
+	}
+
+	// The code only redeems with the verifier it was issued for
+	verifier, nonce := oidc.NewVerifier(), oidc.NewState()
+	authURL, err := p.AuthCodeURL(ctx, "state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := srv.Authorize(authURL, srv.Claims("user-1", "ann@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(ctx, code, oidc.NewVerifier(), nonce); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("wrong verifier: err = %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	srv := oidctest.NewServer("client-1")
+	defer srv.Close()
+
+	d, err := srv.Provider().Discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TokenEndpoint != srv.URL+"/token" || d.JWKSURI != srv.URL+"/jwks" {
+		t.Errorf("discovery = %+v", d)
+	}
+
+	// A document naming another issuer is refused
+	p := srv.Provider()
+	p.Issuer = srv.URL + "/"
+	if _, err := p.Discover(context.Background()); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Errorf("issuer mismatch: err = %v", err)
+	}
+
+	unreachable := &oidc.Provider{Issuer: "http://127.0.0.1:1", ClientID: "client-1"}
+	if _, err := unreachable.AuthCodeURL(context.Background(), "s", "n", "v"); err == nil {
+		t.Error("no error from an unreachable provider")
+	}
+}
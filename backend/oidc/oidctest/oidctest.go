@@ -0,0 +1,169 @@
+// Package oidctest runs a stand-in OpenID Connect provider for tests. It
+// serves a discovery document, a JWKS and a token endpoint, and signs the
+// ID tokens it hands out with its own RSA key.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"interview-prep/oidc"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyID names the provider's signing key in its JWKS and token headers.
+const KeyID = "test-key"
+
+// Server is the provider. Its issuer is its URL.
+type Server struct {
+	*httptest.Server
+	ClientID string
+	Key      *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is what a code redeems for, once.
+type grant struct {
+	idToken   string
+	challenge string
+}
+
+// NewServer starts a provider that clientID is registered with. Callers
+// close it.
+func NewServer(clientID string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{ClientID: clientID, Key: key, codes: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Provider returns the app's side of the registration.
+func (s *Server) Provider() *oidc.Provider {
+	return &oidc.Provider{
+		Name:        "Test",
+		Issuer:      s.URL,
+		ClientID:    s.ClientID,
+		RedirectURL: "http://app.test/auth/callback",
+	}
+}
+
+// Claims are those of a valid ID token for subject with a verified email,
+// lacking only the nonce.
+func (s *Server) Claims(subject, email string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"given_name":     "Test",
+		"family_name":    "User",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+// Sign returns an ID token with claims, signed by key under KeyID.
+func Sign(key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// Authorize does what the provider does once the user signs in at
+// authURL: it returns the code to redeem for an ID token with claims, and
+// the state to hand back with it. The token carries the nonce authURL asks
+// for unless claims set one.
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	if q.Get("client_id") != s.ClientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		return "", "", errors.New("oidctest: not an authorization request for this client")
+	}
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = q.Get("nonce")
+	}
+	return s.Code(Sign(s.Key, claims), q.Get("code_challenge")), q.Get("state"), nil
+}
+
+// Code returns a code that redeems for idToken with the code verifier
+// whose S256 challenge is challenge.
+func (s *Server) Code(idToken, challenge string) string {
+	code := oidc.NewState()
+	s.mu.Lock()
+	s.codes[code] = grant{idToken: idToken, challenge: challenge}
+	s.mu.Unlock()
+	return code
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Discovery{
+		Issuer:                s.URL,
+		AuthorizationEndpoint: s.URL + "/authorize",
+		TokenEndpoint:         s.URL + "/token",
+		JWKSURI:               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.Key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": KeyID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	s.mu.Lock()
+	g, ok := s.codes[r.PostFormValue("code")]
+	delete(s.codes, r.PostFormValue("code"))
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": g.idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
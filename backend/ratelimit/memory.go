@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often Memory drops entries that have nothing left to
+// remember.
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	full   time.Time // when the bucket will be full again
+}
+
+type failures struct {
+	count  int
+	last   time.Time
+	window time.Duration
+	locked time.Time
+}
+
+// since is when the window for the next failure opens: the last failure
+// or, if it locked the key for longer, the end of the lock, so a lock
+// longer than the window doesn't wipe the count it escalates on.
+func (f failures) since() time.Time {
+	if f.locked.After(f.last) {
+		return f.locked
+	}
+	return f.last
+}
+
+// Memory is a Store for a single server.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]bucket
+	failures  map[string]failures
+	lastSweep time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]bucket{}, failures: map[string]failures{}}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = bucket{tokens: float64(limit.Burst), at: now}
+	}
+	b.tokens += float64(now.Sub(b.at)) / float64(limit.Every)
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.at = now
+
+	var wait time.Duration
+	if b.tokens >= 1 {
+		b.tokens--
+	} else {
+		wait = time.Duration((1 - b.tokens) * float64(limit.Every))
+	}
+	b.full = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(limit.Every)))
+	m.buckets[key] = b
+	return wait, nil
+}
+
+func (m *Memory) Fail(ctx context.Context, key string, window time.Duration, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.failures[key]
+	if now.Sub(f.since()) > window {
+		f.count = 0
+	}
+	f.count++
+	f.last = now
+	f.window = window
+	m.failures[key] = f
+	return f.count, nil
+}
+
+func (m *Memory) Lock(ctx context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := m.failures[key]
+	f.locked = until
+	m.failures[key] = f
+	return nil
+}
+
+func (m *Memory) LockedFor(ctx context.Context, key string, now time.Time) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if f, ok := m.failures[key]; ok && f.locked.After(now) {
+		return f.locked.Sub(now), nil
+	}
+	return 0, nil
+}
+
+func (m *Memory) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.failures, key)
+	return nil
+}
+
+// sweep drops full buckets and failures that have expired, so keys seen
+// once don't stay forever. Callers hold m.mu.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepEvery {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if !b.full.After(now) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, f := range m.failures {
+		if now.Sub(f.since()) > f.window {
+			delete(m.failures, key)
+		}
+	}
+}
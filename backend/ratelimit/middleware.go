@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"interview-prep/apperr"
+	"interview-prep/logging"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPeekBody bounds how much of a request body BodyField reads.
+const maxPeekBody = 64 << 10
+
+// Key names what a request counts against. An empty key isn't limited.
+type Key func(c *gin.Context) string
+
+// ClientIP keys requests by the client's address, as far as the trusted
+// proxies tell it.
+func ClientIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// User keys requests by the signed-in user, so it must run after the auth
+// middleware. Requests without one fall back to their address.
+func User(c *gin.Context) string {
+	if id := c.GetInt("user_id"); id != 0 {
+		return "user:" + strconv.Itoa(id)
+	}
+	return ClientIP(c)
+}
+
+// BodyField keys requests by a string field of their JSON body, compared
+// without case, such as the email a login is for. The body is left for the
+// handler to read.
+func BodyField(field string) Key {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBody))
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+		if err != nil {
+			return ""
+		}
+		var body map[string]any
+		if json.Unmarshal(raw, &body) != nil {
+			return ""
+		}
+		value, _ := body[field].(string)
+		if value = strings.ToLower(strings.TrimSpace(value)); value == "" {
+			return ""
+		}
+		return field + ":" + value
+	}
+}
+
+// Both keys requests by a and b together; it is empty if either is.
+func Both(a, b Key) Key {
+	return func(c *gin.Context) string {
+		ka, kb := a(c), b(c)
+		if ka == "" || kb == "" {
+			return ""
+		}
+		return ka + "|" + kb
+	}
+}
+
+// Middleware limits each key to limit, answering 429 with Retry-After when
+// its bucket is empty. name keeps the buckets of different limits apart.
+// When the store fails requests are let through.
+func Middleware(store Store, name string, limit Limit, key Key) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		wait, err := store.Take(c.Request.Context(), name+"/"+k, limit, time.Now())
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("rate limit unavailable", "limit", name, "error", err)
+		} else if wait > 0 {
+			tooMany(c, wait, "rate_limited", "Too many requests; try again later")
+			return
+		}
+		c.Next()
+	}
+}
+
+// Lockout locks a key out after Threshold failures in a row, each within
+// Window of the last or of the end of the lock it caused. The first lock lasts Base and each further failure
+// doubles it, up to Max. A handler fails a request by answering 401 and
+// succeeds by answering 2xx, which clears the key's failures.
+type Lockout struct {
+	Store     Store
+	Name      string
+	Key       Key
+	Threshold int
+	Window    time.Duration
+	Base      time.Duration
+	Max       time.Duration
+}
+
+func (l Lockout) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := l.Key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		k = l.Name + "/" + k
+		ctx := c.Request.Context()
+
+		locked, err := l.Store.LockedFor(ctx, k, time.Now())
+		if err != nil {
+			logging.FromContext(ctx).Error("lockout unavailable", "lockout", l.Name, "error", err)
+		} else if locked > 0 {
+			tooMany(c, locked, "locked_out", "Too many failed attempts; try again later")
+			return
+		}
+
+		c.Next()
+
+		switch status := status(c); {
+		case status == http.StatusUnauthorized:
+			now := time.Now()
+			count, err := l.Store.Fail(ctx, k, l.Window, now)
+			if err == nil && count >= l.Threshold {
+				err = l.Store.Lock(ctx, k, now.Add(l.duration(count)))
+			}
+			if err != nil {
+				logging.FromContext(ctx).Error("lockout could not record a failure", "lockout", l.Name, "error", err)
+			}
+		case status >= 200 && status < 300:
+			if err := l.Store.Reset(ctx, k); err != nil {
+				logging.FromContext(ctx).Error("lockout could not reset", "lockout", l.Name, "error", err)
+			}
+		}
+	}
+}
+
+// duration is how long the count'th failure in a row locks the key.
+func (l Lockout) duration(count int) time.Duration {
+	d := float64(l.Base) * math.Pow(2, float64(count-l.Threshold))
+	if d > float64(l.Max) {
+		return l.Max
+	}
+	return time.Duration(d)
+}
+
+func tooMany(c *gin.Context, wait time.Duration, code, msg string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.Error(apperr.New(apperr.KindTooManyRequests, code, msg))
+	c.Abort()
+}
+
+// status is the status the request is answered with, including the error
+// the error middleware has yet to write.
+func status(c *gin.Context) int {
+	if last := c.Errors.Last(); last != nil && !c.Writer.Written() {
+		return apperr.From(last.Err).Status()
+	}
+	return c.Writer.Status()
+}
@@ -0,0 +1,41 @@
+// Package ratelimit throttles requests with token buckets and locks out
+// keys that keep failing, such as an account under a password guessing
+// attack. State lives behind Store so several servers can share it; Memory
+// keeps it in process.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit lets Burst requests through at once, then one more every Every.
+type Limit struct {
+	Burst int
+	Every time.Duration
+}
+
+// PerMinute lets n requests a minute through, all of them at once if they
+// arrive together.
+func PerMinute(n int) Limit {
+	return Limit{Burst: n, Every: time.Minute / time.Duration(n)}
+}
+
+// Store keeps the buckets and failure counts, by key. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	// Take removes a token from the bucket at key, which refills under
+	// limit. When the bucket is empty it takes nothing and returns how
+	// long until a token is available.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error)
+	// Fail counts a failure at key and returns the number in a row,
+	// forgetting them once window has passed since the last failure and
+	// since the end of any lock on key.
+	Fail(ctx context.Context, key string, window time.Duration, now time.Time) (int, error)
+	// Lock refuses key until until.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedFor returns how long key stays locked, 0 if it isn't.
+	LockedFor(ctx context.Context, key string, now time.Time) (time.Duration, error)
+	// Reset forgets key's failures and lifts its lock.
+	Reset(ctx context.Context, key string) error
+}
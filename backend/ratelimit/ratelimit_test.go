@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A guesser who waits out each lock and tries again is locked for longer
+// each time, up to Max.
+func TestLockoutEscalatesToMax(t *testing.T) {
+	ctx := context.Background()
+	l := Lockout{Store: NewMemory(), Threshold: 5, Window: 15 * time.Minute, Base: time.Minute, Max: time.Hour}
+	now := time.Now()
+	fail := func() time.Duration {
+		t.Helper()
+		count, err := l.Store.Fail(ctx, "key", l.Window, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count < l.Threshold {
+			return 0
+		}
+		d := l.duration(count)
+		if err := l.Store.Lock(ctx, "key", now.Add(d)); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	for i := 1; i < l.Threshold; i++ {
+		if d := fail(); d != 0 {
+			t.Fatalf("failure %d locked for %s", i, d)
+		}
+	}
+	want := []time.Duration{1, 2, 4, 8, 16, 32, 60, 60}
+	for i, minutes := range want {
+		d := fail()
+		if d != minutes*time.Minute {
+			t.Fatalf("lock %d lasted %s, want %s", i+1, d, minutes*time.Minute)
+		}
+		now = now.Add(d + time.Second)
+		if locked, _ := l.Store.LockedFor(ctx, "key", now); locked != 0 {
+			t.Fatalf("still locked for %s after lock %d", locked, i+1)
+		}
+	}
+
+	// A window after the lock ends, the key starts over
+	now = now.Add(l.Window)
+	for i := 1; i < l.Threshold; i++ {
+		if d := fail(); d != 0 {
+			t.Fatalf("failure %d after a quiet window locked for %s", i, d)
+		}
+	}
+	if d := fail(); d != l.Base {
+		t.Errorf("first lock after a quiet window lasted %s, want %s", d, l.Base)
+	}
+}
@@ -0,0 +1,64 @@
+// Package review schedules questions for spaced repetition using the SM-2
+// algorithm: each review is graded 0-5, correct answers push the next review
+// further out by the card's ease factor, and lapses start it over.
+package review
+
+import (
+	"interview-prep/models"
+	"math"
+	"time"
+)
+
+const (
+	MinGrade = 0
+	MaxGrade = 5
+	// PassingGrade is the lowest grade that counts as remembering the answer.
+	PassingGrade = 3
+	// MatureIntervalDays is the review interval from which a question
+	// counts as mastered.
+	MatureIntervalDays = 21
+
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// NewState returns the state of a question the user has never reviewed.
+func NewState(userID, questionID int) models.ReviewState {
+	return models.ReviewState{
+		UserID:     userID,
+		QuestionID: questionID,
+		EaseFactor: DefaultEaseFactor,
+	}
+}
+
+// Schedule applies a review graded grade at now and returns the new state.
+func Schedule(s models.ReviewState, grade int, now time.Time) models.ReviewState {
+	if grade < PassingGrade {
+		if s.Repetitions > 0 {
+			s.Lapses++
+		}
+		s.Repetitions = 0
+		s.IntervalDays = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.IntervalDays = 1
+		case 1:
+			s.IntervalDays = 6
+		default:
+			s.IntervalDays = int(math.Round(float64(s.IntervalDays) * s.EaseFactor))
+		}
+		s.Repetitions++
+	}
+
+	q := float64(MaxGrade - grade)
+	s.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if s.EaseFactor < MinEaseFactor {
+		s.EaseFactor = MinEaseFactor
+	}
+
+	s.LastGrade = grade
+	s.LastReviewedAt = now
+	s.DueAt = now.AddDate(0, 0, s.IntervalDays)
+	return s
+}
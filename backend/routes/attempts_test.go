@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"interview-prep/grading"
+	"interview-prep/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// attemptPath adds a question to a new category of the caller's and
+// returns its attempt endpoint.
+func attemptPath(s *testServer, token string) string {
+	s.t.Helper()
+	r := s.do("POST", "/api/categories", token, map[string]string{"name": "Go"})
+	s.expect(r, http.StatusCreated, "")
+	r = s.do("POST", "/api/questions", token, map[string]any{
+		"category_id": r.Body["id"], "question": "What is a goroutine?",
+		"answer": "A goroutine is a [[lightweight thread]] managed by the [[Go runtime]].",
+	})
+	s.expect(r, http.StatusCreated, "")
+	return "/api/questions/" + strconv.Itoa(int(r.Body["id"].(float64))) + "/attempt"
+}
+
+func TestAttemptGraders(t *testing.T) {
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"score\": 0.9, \"missed\": []}"}}]}`))
+	}))
+	defer model.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+	outOfRange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"score\": 7}"}}]}`))
+	}))
+	defer outOfRange.Close()
+
+	grader := func(url string) *grading.HTTP { return grading.NewHTTP(url, "test-model", "", 100*time.Millisecond) }
+	tests := []struct {
+		name   string
+		grader grading.Grader
+		status int
+		code   string
+		by     string
+	}{
+		{"model", grader(model.URL), http.StatusOK, "", "http:test-model"},
+		{"falls back on a server error", grading.Chain{grader(broken.URL), grading.Rules{}}, http.StatusOK, "", "rules"},
+		{"falls back on a timeout", grading.Chain{grader(slow.URL), grading.Rules{}}, http.StatusOK, "", "rules"},
+		{"falls back on an out of range score", grading.Chain{grader(outOfRange.URL), grading.Rules{}}, http.StatusOK, "", "rules"},
+		{"server error", grader(broken.URL), http.StatusServiceUnavailable, "grading_failed", ""},
+		{"timeout", grader(slow.URL), http.StatusServiceUnavailable, "grading_failed", ""},
+		{"out of range score", grader(outOfRange.URL), http.StatusServiceUnavailable, "grading_failed", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.handler.Grader = tt.grader
+			_, token := s.user(models.RoleUser)
+			path := attemptPath(s, token)
+
+			r := s.do("POST", path, token, map[string]string{"answer": "A lightweight thread run by the Go runtime"})
+			s.expect(r, tt.status, tt.code)
+			if tt.status == http.StatusOK {
+				if r.Body["grader"] != tt.by || r.Body["expected_answer"] != "A goroutine is a lightweight thread managed by the Go runtime." {
+					t.Errorf("response = %s", r.Raw)
+				}
+				return
+			}
+			if p := problem(t, r); p.Title != "Service Unavailable" || p.Detail == "" {
+				t.Errorf("problem = %+v", p)
+			}
+		})
+	}
+}
+
+func TestAttemptValidation(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.user(models.RoleUser)
+	path := attemptPath(s, token)
+	s.expect(s.do("POST", path, token, map[string]string{"answer": "  "}), http.StatusBadRequest, "answer_required")
+	s.expect(s.do("POST", "/api/questions/999/attempt", token, map[string]string{"answer": "x"}), http.StatusNotFound, "question_not_found")
+
+	r := s.do("POST", "/api/categories", token, map[string]string{"name": "Unanswered"})
+	s.expect(r, http.StatusCreated, "")
+	r = s.do("POST", "/api/questions", token, map[string]any{"category_id": r.Body["id"], "question": "What is a goroutine?"})
+	s.expect(r, http.StatusCreated, "")
+	path = "/api/questions/" + strconv.Itoa(int(r.Body["id"].(float64))) + "/attempt"
+	s.expect(s.do("POST", path, token, map[string]string{"answer": "A lightweight thread"}), http.StatusUnprocessableEntity, "no_reference_answer")
+}
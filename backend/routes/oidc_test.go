@@ -0,0 +1,195 @@
+package routes
+
+import (
+	"interview-prep/models"
+	"interview-prep/oidc/oidctest"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// withOIDC turns on single sign-on against a stand-in provider.
+func (s *testServer) withOIDC() *oidctest.Server {
+	s.t.Helper()
+	srv := oidctest.NewServer("client-1")
+	s.t.Cleanup(srv.Close)
+	s.controller.OIDC = srv.Provider()
+	return srv
+}
+
+// startOIDC begins a sign-in and returns the URL the user is sent to.
+func (s *testServer) startOIDC() string {
+	s.t.Helper()
+	r := s.do("GET", "/auth/oidc/start", "", nil)
+	s.expect(r, http.StatusOK, "")
+	authURL, _ := r.Body["authorization_url"].(string)
+	return authURL
+}
+
+// oidcSignIn signs in at the provider, which issues an ID token with
+// claims, and hands the result to the callback.
+func (s *testServer) oidcSignIn(srv *oidctest.Server, claims jwt.MapClaims) response {
+	s.t.Helper()
+	code, state, err := srv.Authorize(s.startOIDC(), claims)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": code, "state": state})
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	s := newTestServer(t)
+	r := s.do("GET", "/auth/oidc", "", nil)
+	s.expect(r, http.StatusOK, "")
+	if r.Body["enabled"] != false {
+		t.Errorf("config = %s", r.Raw)
+	}
+	s.expect(s.do("GET", "/auth/oidc/start", "", nil), http.StatusNotFound, "oidc_disabled")
+	s.expect(s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": "c", "state": "s"}), http.StatusNotFound, "oidc_disabled")
+}
+
+func TestOIDCSignUp(t *testing.T) {
+	s := newTestServer(t)
+	srv := s.withOIDC()
+	r := s.do("GET", "/auth/oidc", "", nil)
+	if r.Body["enabled"] != true || r.Body["name"] != "Test" {
+		t.Errorf("config = %s", r.Raw)
+	}
+
+	r = s.oidcSignIn(srv, srv.Claims("subject-1", "new@example.com"))
+	s.expect(r, http.StatusOK, "")
+	user, _ := r.Body["user"].(map[string]any)
+	if r.Body["created"] != true || r.Body["token"] == "" || user["email"] != "new@example.com" || user["first_name"] != "Test" {
+		t.Fatalf("response = %s", r.Raw)
+	}
+	if _, ok := user["password"]; ok {
+		t.Errorf("response leaks the password: %s", r.Raw)
+	}
+
+	// The same subject signs in to the same account, whatever its email now
+	again := s.oidcSignIn(srv, srv.Claims("subject-1", "renamed@example.com"))
+	s.expect(again, http.StatusOK, "")
+	if again.Body["created"] != false || again.Body["user"].(map[string]any)["id"] != user["id"] {
+		t.Errorf("second sign-in = %s", again.Raw)
+	}
+}
+
+func TestOIDCLinksVerifiedEmail(t *testing.T) {
+	s := newTestServer(t)
+	srv := s.withOIDC()
+	id, _ := s.user(models.RoleUser)
+
+	r := s.oidcSignIn(srv, srv.Claims("subject-1", "user1@example.com"))
+	s.expect(r, http.StatusOK, "")
+	user := r.Body["user"].(map[string]any)
+	if r.Body["created"] != false || user["id"] != float64(id) || user["first_name"] != "User" {
+		t.Errorf("response = %s", r.Raw)
+	}
+}
+
+// An account made with someone else's address must not survive its owner
+// signing in through the provider.
+func TestOIDCTakesOverUnverifiedAccount(t *testing.T) {
+	s := newTestServer(t)
+	srv := s.withOIDC()
+	signup := map[string]string{
+		"first_name": "Eve", "last_name": "Lee", "email": "ann@example.com", "password": "secret123", "phone": "555-0100",
+	}
+	s.expect(s.do("POST", "/signup", "", signup), http.StatusCreated, "")
+	squatter, err := s.store.GetUserByEmail(t.Context(), "ann@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := models.Session{ID: "squatter-session", UserID: squatter.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.store.CreateSession(t.Context(), &session, "refresh-hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.oidcSignIn(srv, srv.Claims("subject-1", "ann@example.com"))
+	s.expect(r, http.StatusOK, "")
+	if r.Body["created"] != false || r.Body["user"].(map[string]any)["id"] != float64(squatter.ID) {
+		t.Errorf("response = %s", r.Raw)
+	}
+	if active, err := s.store.SessionActive(t.Context(), session.ID); err != nil || active {
+		t.Errorf("squatter's session active = %v, %v", active, err)
+	}
+	s.expect(s.do("POST", "/login", "", map[string]string{"email": "ann@example.com", "password": "secret123"}),
+		http.StatusUnauthorized, "invalid_credentials")
+}
+
+func TestOIDCUnverifiedEmail(t *testing.T) {
+	s := newTestServer(t)
+	srv := s.withOIDC()
+	s.user(models.RoleUser)
+
+	// An unverified email cannot claim an existing account
+	claims := srv.Claims("subject-1", "user1@example.com")
+	claims["email_verified"] = false
+	s.expect(s.oidcSignIn(srv, claims), http.StatusConflict, "identity_not_linked")
+
+	// A new account is made but must verify its email first
+	claims = srv.Claims("subject-2", "new@example.com")
+	claims["email_verified"] = false
+	s.expect(s.oidcSignIn(srv, claims), http.StatusForbidden, "email_unverified")
+	s.expect(s.do("POST", "/auth/verify-email", "", map[string]string{"token": s.mailToken()}), http.StatusOK, "")
+	r := s.oidcSignIn(srv, srv.Claims("subject-2", "new@example.com"))
+	s.expect(r, http.StatusOK, "")
+	if r.Body["created"] != false {
+		t.Errorf("response = %s", r.Raw)
+	}
+
+	claims = srv.Claims("subject-3", "")
+	s.expect(s.oidcSignIn(srv, claims), http.StatusBadRequest, "oidc_email_missing")
+}
+
+func TestOIDCState(t *testing.T) {
+	s := newTestServer(t)
+	srv := s.withOIDC()
+
+	code, state, err := srv.Authorize(s.startOIDC(), srv.Claims("subject-1", "new@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": code, "state": "forged"}), http.StatusBadRequest, "oidc_login_expired")
+	s.expect(s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": code, "state": state}), http.StatusOK, "")
+	// Each state is good for one callback
+	s.expect(s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": code, "state": state}), http.StatusBadRequest, "oidc_login_expired")
+
+	// A state from another sign-in does not carry that sign-in's nonce
+	first := s.startOIDC()
+	code, _, err = srv.Authorize(first, srv.Claims("subject-1", "new@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherState, err := srv.Authorize(s.startOIDC(), srv.Claims("subject-1", "new@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do("POST", "/auth/oidc/callback", "", map[string]string{"code": code, "state": otherState}), http.StatusUnauthorized, "oidc_exchange_failed")
+}
+
+func TestOIDCRejectsBadTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+	}{
+		{"nonce mismatch", func(c jwt.MapClaims) { c["nonce"] = "someone-elses-nonce" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "client-2" }},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			srv := s.withOIDC()
+			claims := srv.Claims("subject-1", "new@example.com")
+			tt.change(claims)
+			s.expect(s.oidcSignIn(srv, claims), http.StatusUnauthorized, "oidc_exchange_failed")
+			if u, _ := s.store.GetUserByEmail(t.Context(), "new@example.com"); u != nil {
+				t.Errorf("made an account from a rejected token: %+v", u)
+			}
+		})
+	}
+}
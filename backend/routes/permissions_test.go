@@ -0,0 +1,227 @@
+package routes
+
+import (
+	"encoding/json"
+	"interview-prep/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The callers every permission case is tried as. Members were approved
+// with the default contributor role.
+const (
+	owner    = "owner"
+	member   = "member"
+	pending  = "pending"
+	rejected = "rejected"
+	stranger = "stranger"
+	admin    = "admin"
+)
+
+var actors = []string{owner, member, pending, rejected, stranger, admin}
+
+// permissionWorld is a category with a member, a pending and a rejected
+// access request, and questions by the owner and the member.
+type permissionWorld struct {
+	*testServer
+	tokens map[string]string
+	ids    map[string]int
+
+	categoryID      int
+	ownerQuestion   int
+	memberQuestion  int
+	pendingRequest  int
+	rejectedRequest int
+}
+
+func newPermissionWorld(t *testing.T) *permissionWorld {
+	t.Helper()
+	w := &permissionWorld{testServer: newTestServer(t), tokens: map[string]string{}, ids: map[string]int{}}
+	for _, actor := range actors {
+		role := models.RoleUser
+		if actor == admin {
+			role = models.RoleAdmin
+		}
+		w.ids[actor], w.tokens[actor] = w.user(role)
+	}
+
+	r := w.do("POST", "/api/categories", w.tokens[owner], map[string]string{"name": "Go"})
+	w.expect(r, http.StatusCreated, "")
+	w.categoryID = int(r.Body["id"].(float64))
+	w.ownerQuestion = w.createQuestion(owner, "What is a goroutine?", "A lightweight thread")
+
+	for _, actor := range []string{member, pending, rejected} {
+		w.expect(w.do("POST", w.path("/api/categories/{cat}/request-access"), w.tokens[actor], nil), http.StatusCreated, "")
+	}
+	r = w.do("GET", w.path("/api/categories/{cat}/requests"), w.tokens[owner], nil)
+	w.expect(r, http.StatusOK, "")
+	var pendingRequests []models.AccessRequest
+	if err := json.Unmarshal([]byte(r.Raw), &pendingRequests); err != nil {
+		t.Fatal(err)
+	}
+	requests := map[int]int{}
+	for _, req := range pendingRequests {
+		requests[req.UserID] = req.ID
+	}
+	w.pendingRequest = requests[w.ids[pending]]
+	w.rejectedRequest = requests[w.ids[rejected]]
+	w.respond(requests[w.ids[member]], "APPROVED")
+	w.respond(w.rejectedRequest, "REJECTED")
+
+	w.memberQuestion = w.createQuestion(member, "What is a channel?", "A typed pipe")
+	return w
+}
+
+func (w *permissionWorld) createQuestion(actor, question, answer string) int {
+	w.t.Helper()
+	r := w.do("POST", "/api/questions", w.tokens[actor], map[string]any{"category_id": w.categoryID, "question": question, "answer": answer})
+	w.expect(r, http.StatusCreated, "")
+	return int(r.Body["id"].(float64))
+}
+
+func (w *permissionWorld) respond(requestID int, status string) {
+	w.t.Helper()
+	path := w.path("/api/categories/{cat}/requests/" + strconv.Itoa(requestID) + "/respond")
+	w.expect(w.do("POST", path, w.tokens[owner], map[string]string{"status": status}), http.StatusOK, "")
+}
+
+// path fills in the ids a case's path refers to.
+func (w *permissionWorld) path(p string) string {
+	return strings.NewReplacer(
+		"{cat}", strconv.Itoa(w.categoryID),
+		"{q}", strconv.Itoa(w.ownerQuestion),
+		"{memberQ}", strconv.Itoa(w.memberQuestion),
+		"{pendingReq}", strconv.Itoa(w.pendingRequest),
+		"{rejectedReq}", strconv.Itoa(w.rejectedRequest),
+		"{member}", strconv.Itoa(w.ids[member]),
+	).Replace(p)
+}
+
+type outcome struct {
+	status int
+	code   string
+}
+
+var (
+	ok          = outcome{http.StatusOK, ""}
+	created     = outcome{http.StatusCreated, ""}
+	forbidden   = outcome{http.StatusForbidden, "forbidden"}
+	hidden      = outcome{http.StatusNotFound, "question_not_found"}
+	answered    = outcome{http.StatusConflict, "request_answered"}
+	incomplete  = outcome{http.StatusUnprocessableEntity, "incomplete_question"}
+	ownerOnly   = map[string]outcome{owner: ok, member: forbidden, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: ok}
+	viewers     = map[string]outcome{owner: ok, member: ok, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: ok}
+	questionUse = map[string]outcome{owner: ok, member: ok, pending: hidden, rejected: hidden, stranger: hidden, admin: ok}
+)
+
+func TestPermissionMatrix(t *testing.T) {
+	fullQuestion := map[string]any{"question": "What is a goroutine?", "answer": "", "context": "", "difficulty": "Hard", "tags": []string{}}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   map[string]outcome
+	}{
+		// Categories
+		{"list categories", "GET", "/api/categories", nil,
+			map[string]outcome{owner: ok, member: ok, pending: ok, rejected: ok, stranger: ok, admin: ok}},
+		{"delete category", "DELETE", "/api/categories/{cat}", nil, ownerOnly},
+		{"transfer category", "POST", "/api/categories/{cat}/transfer", `{"user_id": {member}}`, ownerOnly},
+		{"create tag", "POST", "/api/categories/{cat}/tags", map[string]string{"name": "concurrency"},
+			map[string]outcome{owner: created, member: created, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: created}},
+		{"import", "POST", "/api/categories/{cat}/import?format=json", `[{"question": "What is a mutex?", "answer": ""}]`,
+			map[string]outcome{owner: created, member: created, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: created}},
+		{"export", "GET", "/api/categories/{cat}/export?format=json", nil, viewers},
+		{"export csv", "GET", "/api/categories/{cat}/export?format=csv", nil, viewers},
+
+		// Access requests and members; only owners and editors manage them
+		{"list requests", "GET", "/api/categories/{cat}/requests", nil, ownerOnly},
+		{"approve request", "POST", "/api/categories/{cat}/requests/{pendingReq}/respond", map[string]string{"status": "APPROVED"}, ownerOnly},
+		{"reject request", "POST", "/api/categories/{cat}/requests/{pendingReq}/respond", map[string]string{"status": "REJECTED"}, ownerOnly},
+		{"approve as editor", "POST", "/api/categories/{cat}/requests/{pendingReq}/respond", map[string]string{"status": "APPROVED", "role": "editor"}, ownerOnly},
+		// An answered request is only reported as such to those who may
+		// manage it
+		{"answer answered request", "POST", "/api/categories/{cat}/requests/{rejectedReq}/respond", map[string]string{"status": "APPROVED"},
+			map[string]outcome{owner: answered, member: forbidden, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: answered}},
+		{"answer missing request", "POST", "/api/categories/{cat}/requests/999/respond", map[string]string{"status": "APPROVED"},
+			map[string]outcome{owner: {http.StatusNotFound, "request_not_found"}, member: forbidden, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: {http.StatusNotFound, "request_not_found"}}},
+		{"list members", "GET", "/api/categories/{cat}/members", nil, ownerOnly},
+		{"set member role", "PUT", "/api/categories/{cat}/members/{member}", map[string]string{"role": "viewer"}, ownerOnly},
+		{"remove member", "DELETE", "/api/categories/{cat}/members/{member}", nil, ownerOnly},
+
+		// Questions; those outside the category don't learn they exist
+		{"list questions", "GET", "/api/questions?category_id={cat}", nil, viewers},
+		{"create question", "POST", "/api/questions", `{"category_id": {cat}, "question": "What is select?"}`,
+			map[string]outcome{owner: created, member: created, pending: forbidden, rejected: forbidden, stranger: forbidden, admin: created}},
+		{"replace question", "PUT", "/api/questions/{q}", fullQuestion,
+			map[string]outcome{owner: ok, member: forbidden, pending: hidden, rejected: hidden, stranger: hidden, admin: ok}},
+		// Only those who may edit the question hear what is wrong with the
+		// replacement
+		{"replace question partly", "PUT", "/api/questions/{q}", map[string]string{"question": "What is a goroutine?"},
+			map[string]outcome{owner: incomplete, member: forbidden, pending: hidden, rejected: hidden, stranger: hidden, admin: incomplete}},
+		{"patch question", "PATCH", "/api/questions/{q}", map[string]string{"answer": "A green thread"},
+			map[string]outcome{owner: ok, member: forbidden, pending: hidden, rejected: hidden, stranger: hidden, admin: ok}},
+		{"delete question", "DELETE", "/api/questions/{q}", nil,
+			map[string]outcome{owner: ok, member: forbidden, pending: hidden, rejected: hidden, stranger: hidden, admin: ok}},
+		{"patch own question", "PATCH", "/api/questions/{memberQ}", map[string]string{"answer": "A typed pipe"}, questionUse},
+		{"delete own question", "DELETE", "/api/questions/{memberQ}", nil, questionUse},
+		{"attempt question", "POST", "/api/questions/{q}/attempt", map[string]string{"answer": "a goroutine"}, questionUse},
+		{"question revisions", "GET", "/api/questions/{q}/revisions", nil, questionUse},
+		{"review question", "POST", "/api/review/{q}", map[string]int{"grade": 4}, questionUse},
+	}
+
+	for _, tt := range tests {
+		for _, actor := range actors {
+			t.Run(tt.name+"/"+actor, func(t *testing.T) {
+				w := newPermissionWorld(t)
+				body := tt.body
+				if raw, ok := body.(string); ok {
+					body = w.path(raw)
+				}
+				want := tt.want[actor]
+				r := w.do(tt.method, w.path(tt.path), w.tokens[actor], body)
+				w.expect(r, want.status, want.code)
+			})
+		}
+	}
+}
+
+func TestRequestAccess(t *testing.T) {
+	w := newPermissionWorld(t)
+	path := w.path("/api/categories/{cat}/request-access")
+	w.expect(w.do("POST", path, w.tokens[stranger], nil), http.StatusCreated, "")
+	for _, actor := range []string{member, pending, rejected, stranger} {
+		w.expect(w.do("POST", path, w.tokens[actor], nil), http.StatusConflict, "request_exists")
+	}
+	w.expect(w.do("POST", "/api/categories/999/request-access", w.tokens[stranger], nil), http.StatusNotFound, "category_not_found")
+}
+
+// Questions a caller can't see are left out of lists and search rather
+// than refused. Admins see them all, as they may open each one.
+func TestOnlyVisibleQuestionsAreListed(t *testing.T) {
+	w := newPermissionWorld(t)
+	for actor, want := range map[string]struct{ listed, found int }{
+		owner: {2, 1}, member: {2, 1}, pending: {0, 0}, rejected: {0, 0}, stranger: {0, 0}, admin: {2, 1},
+	} {
+		r := w.do("GET", "/api/questions", w.tokens[actor], nil)
+		w.expect(r, http.StatusOK, "")
+		var page struct {
+			Items []models.Question `json:"items"`
+		}
+		if err := json.Unmarshal([]byte(r.Raw), &page); err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Items) != want.listed {
+			t.Errorf("%s listed %d questions, want %d", actor, len(page.Items), want.listed)
+		}
+
+		r = w.do("GET", "/api/questions/search?q=goroutine", w.tokens[actor], nil)
+		w.expect(r, http.StatusOK, "")
+		if n := strings.Count(r.Raw, `"rank"`); n != want.found {
+			t.Errorf("%s found %d questions, want %d", actor, n, want.found)
+		}
+	}
+}
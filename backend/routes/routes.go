@@ -1,23 +1,119 @@
 package routes
 
 import (
-	"database/sql"
 	"interview-prep/controllers"
-	"interview-prep/middleware"
+	"interview-prep/handlers"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, db *sql.DB) {
-	uc := &controllers.UserController{DB: db}
+// Limits are the rate limiting middlewares of each route group, run in
+// order. Empty groups aren't limited.
+type Limits struct {
+	Login   []gin.HandlerFunc // POST /login
+	Account []gin.HandlerFunc // the other endpoints that need no sign-in
+	API     []gin.HandlerFunc // signed-in requests, after the auth check
+}
+
+// SetupHealthRoutes registers the probes, outside rate limits and sign-in.
+func SetupHealthRoutes(router *gin.Engine, health *handlers.Health) {
+	router.GET("/healthz", health.Live)
+	router.GET("/readyz", health.Ready)
+}
 
-	router.POST("/signup", uc.Signup)
-	router.POST("/login", uc.Login)
+func SetupRoutes(router *gin.Engine, uc *controllers.UserController, auth gin.HandlerFunc, limits Limits) {
+	router.POST("/login", append(slices.Clip(limits.Login), uc.Login)...)
+
+	account := router.Group("/")
+	account.Use(limits.Account...)
+	{
+		account.POST("/signup", uc.Signup)
+		account.POST("/auth/refresh", uc.Refresh)
+		account.POST("/auth/verify-email", uc.VerifyEmail)
+		account.POST("/auth/resend-verification", uc.ResendVerification)
+		account.POST("/auth/forgot-password", uc.ForgotPassword)
+		account.POST("/auth/reset-password", uc.ResetPassword)
+		account.GET("/auth/oidc", uc.GetOIDCConfig)
+		account.GET("/auth/oidc/start", uc.StartOIDCLogin)
+		account.POST("/auth/oidc/callback", uc.OIDCCallback)
+	}
 
 	protected := router.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(auth)
+	protected.Use(limits.API...)
 	{
+		protected.POST("/auth/logout", uc.Logout)
+		protected.POST("/auth/logout-all", uc.LogoutAll)
+
+		protected.GET("/me", uc.GetMe)
+		protected.PUT("/me", uc.UpdateMe)
+		protected.PUT("/me/password", uc.ChangeMyPassword)
+
 		protected.GET("/users", uc.GetUsers)
 		protected.GET("/users/:id", uc.GetUser)
+		protected.PUT("/users/:id", uc.UpdateUser)
+		protected.DELETE("/users/:id", uc.DeleteUser)
+		protected.GET("/users/:id/summary", uc.GetUserSummary)
+		protected.POST("/users/:id/suspend", uc.SuspendUser)
+		protected.POST("/users/:id/unsuspend", uc.UnsuspendUser)
+		protected.PUT("/users/:id/role", uc.SetUserRole)
+		protected.POST("/users/:id/lock", uc.LockUser)
+		protected.POST("/users/:id/unlock", uc.UnlockUser)
+		protected.POST("/users/:id/force-password-reset", uc.ForcePasswordReset)
+		protected.GET("/audit-log", uc.GetAuditLog)
+	}
+}
+
+func SetupAPIRoutes(router *gin.Engine, h *handlers.Handler, auth gin.HandlerFunc, limits Limits) {
+	api := router.Group("/api")
+	api.Use(auth)
+	api.Use(limits.API...)
+	{
+		api.GET("/categories", h.GetCategories)
+		api.POST("/categories", h.CreateCategory)
+		api.DELETE("/categories/:id", h.DeleteCategory)
+		api.POST("/categories/:id/import", h.ImportQuestions)
+		api.GET("/categories/:id/export", h.ExportCategory)
+		api.POST("/categories/:id/request-access", h.RequestAccess)
+		api.GET("/categories/:id/requests", h.GetRequests)
+		api.POST("/categories/:id/requests/:requestId/respond", h.RespondToRequest)
+		api.GET("/categories/:id/members", h.GetMembers)
+		api.PUT("/categories/:id/members/:userId", h.SetMemberRole)
+		api.DELETE("/categories/:id/members/:userId", h.RemoveMember)
+		api.POST("/categories/:id/transfer", h.TransferCategory)
+		api.POST("/categories/:id/tags", h.CreateTag)
+
+		api.GET("/questions", h.GetQuestions)
+		api.GET("/questions/search", h.SearchQuestions)
+		api.POST("/questions", h.CreateQuestion)
+		api.PUT("/questions/:id", h.UpdateQuestion)
+		api.PATCH("/questions/:id", h.PatchQuestion)
+		api.DELETE("/questions/:id", h.DeleteQuestion)
+		api.POST("/questions/:id/attempt", h.AttemptQuestion)
+		api.GET("/questions/:id/revisions", h.GetRevisions)
+		api.GET("/questions/:id/revisions/diff", h.DiffRevisions)
+		api.POST("/questions/:id/revisions/:rev/restore", h.RestoreRevision)
+
+		api.GET("/tags", h.GetTags)
+		api.GET("/tags/autocomplete", h.SuggestTags)
+		api.PUT("/tags/:id", h.RenameTag)
+		api.DELETE("/tags/:id", h.DeleteTag)
+
+		api.GET("/trash", h.GetTrash)
+		api.POST("/trash/:id/restore", h.RestoreQuestion)
+
+		api.GET("/review/due", h.GetDueReviews)
+		api.POST("/review/:questionId", h.SubmitReview)
+
+		api.GET("/interviews", h.GetInterviews)
+		api.POST("/interviews", h.CreateInterview)
+		api.GET("/interviews/:id", h.GetInterview)
+		api.GET("/interviews/:id/next", h.NextInterviewQuestion)
+		api.POST("/interviews/:id/answer", h.SubmitInterviewAnswer)
+		api.POST("/interviews/:id/complete", h.CompleteInterview)
+
+		api.GET("/me/stats", h.GetMyStats)
+		api.POST("/me/events", h.RecordStudyEvent)
 	}
 }
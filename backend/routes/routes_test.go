@@ -0,0 +1,432 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"interview-prep/apperr"
+	"interview-prep/config"
+	"interview-prep/controllers"
+	"interview-prep/grading"
+	"interview-prep/handlers"
+	"interview-prep/helpers"
+	"interview-prep/mail"
+	"interview-prep/middleware"
+	"interview-prep/models"
+	"interview-prep/store"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testServer is the whole API over a Memory store, with mail kept in an
+// outbox instead of being sent.
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	store  *store.Memory
+	// handler serves the API routes; tests may swap its dependencies
+	handler *handlers.Handler
+	// controller serves the account routes; OIDC is off until a test sets it
+	controller *controllers.UserController
+	tokens     *helpers.Tokens
+	outbox     chan mail.Message
+	users      int
+}
+
+type outbox chan mail.Message
+
+func (o outbox) Send(ctx context.Context, m mail.Message) error {
+	o <- m
+	return nil
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	s := &testServer{
+		t:      t,
+		store:  store.NewMemory(),
+		tokens: helpers.NewTokens("test-secret"),
+		outbox: make(chan mail.Message, 10),
+	}
+	m := s.store
+	s.router = gin.New()
+	s.router.Use(middleware.Errors())
+	s.router.NoRoute(middleware.NoRoute)
+	s.handler = &handlers.Handler{
+		Categories: m, Questions: m, Tags: m, Permissions: m, Reviews: m, Interviews: m, Stats: m,
+		Grader: grading.Rules{},
+	}
+	s.controller = &controllers.UserController{
+		Users: m, Admin: m, Accounts: m, Identities: m, Sessions: m,
+		Mailer: outbox(s.outbox), Tokens: s.tokens, AppURL: "http://app.test",
+	}
+	auth := middleware.AuthMiddleware(m, s.tokens)
+	SetupRoutes(s.router, s.controller, auth, Limits{})
+	SetupAPIRoutes(s.router, s.handler, auth, Limits{})
+	return s
+}
+
+// response is a recorded response with its JSON body decoded.
+type response struct {
+	Code int
+	Body map[string]any
+	Raw  string
+}
+
+func (s *testServer) do(method, path, token string, body any) response {
+	s.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if raw, ok := body.(string); ok {
+			buf.WriteString(raw)
+		} else if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	r := response{Code: w.Code, Raw: w.Body.String()}
+	json.Unmarshal(w.Body.Bytes(), &r.Body)
+	return r
+}
+
+// problemCode is the code of a problem details body.
+func (r response) problemCode() string {
+	code, _ := r.Body["code"].(string)
+	return code
+}
+
+// expect fails the test unless r has status and, for errors, the problem
+// code.
+func (s *testServer) expect(r response, status int, code string) {
+	s.t.Helper()
+	if r.Code != status {
+		s.t.Fatalf("status = %d, want %d: %s", r.Code, status, r.Raw)
+	}
+	if status >= 400 && r.problemCode() != code {
+		s.t.Fatalf("code = %q, want %q: %s", r.problemCode(), code, r.Raw)
+	}
+}
+
+// user adds a verified account with role straight to the store and signs
+// it in, skipping the password hashing of a real signup.
+func (s *testServer) user(role string) (int, string) {
+	s.t.Helper()
+	ctx := context.Background()
+	s.users++
+	n := strconv.Itoa(s.users)
+	now := time.Now()
+	u := &models.User{
+		FirstName: "User", LastName: n, Email: "user" + n + "@example.com", Phone: "555-000" + n,
+		Role: role, EmailVerifiedAt: &now,
+	}
+	if err := s.store.CreateUser(ctx, u); err != nil {
+		s.t.Fatal(err)
+	}
+	session := models.Session{ID: config.GenerateRandomKey(), UserID: u.ID, ExpiresAt: now.Add(time.Hour)}
+	_, refreshHash := helpers.GenerateRefreshToken()
+	if err := s.store.CreateSession(ctx, &session, refreshHash); err != nil {
+		s.t.Fatal(err)
+	}
+	token, err := s.tokens.GenerateToken(u.Email, u.ID, u.Role, session.ID)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return u.ID, token
+}
+
+// mailToken takes the next mail from the outbox and returns the token in
+// its link.
+func (s *testServer) mailToken() string {
+	s.t.Helper()
+	select {
+	case m := <-s.outbox:
+		_, link, ok := strings.Cut(m.Body, "?token=")
+		if !ok {
+			s.t.Fatalf("mail has no token link: %q", m.Body)
+		}
+		link, _, _ = strings.Cut(link, "\n")
+		token, err := url.QueryUnescape(strings.TrimSpace(link))
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		return token
+	case <-time.After(2 * time.Second):
+		s.t.Fatal("no mail sent")
+	}
+	return ""
+}
+
+func TestSignupVerifyAndLogin(t *testing.T) {
+	s := newTestServer(t)
+	signup := map[string]string{
+		"first_name": "Ann", "last_name": "Lee", "email": "ann@example.com", "password": "secret123", "phone": "555-0100",
+	}
+
+	r := s.do("POST", "/signup", "", signup)
+	s.expect(r, http.StatusCreated, "")
+	if strings.Contains(r.Raw, `"password"`) {
+		t.Errorf("signup response has the password: %s", r.Raw)
+	}
+
+	s.expect(s.do("POST", "/login", "", map[string]string{"email": "ann@example.com", "password": "secret123"}),
+		http.StatusForbidden, "email_unverified")
+	s.expect(s.do("POST", "/auth/verify-email", "", map[string]string{"token": s.mailToken()}), http.StatusOK, "")
+
+	s.expect(s.do("POST", "/login", "", map[string]string{"email": "ann@example.com", "password": "wrong-password"}),
+		http.StatusUnauthorized, "invalid_credentials")
+	s.expect(s.do("POST", "/login", "", map[string]string{"email": "nobody@example.com", "password": "secret123"}),
+		http.StatusUnauthorized, "invalid_credentials")
+	r = s.do("POST", "/login", "", map[string]string{"email": "ann@example.com", "password": "secret123"})
+	s.expect(r, http.StatusOK, "")
+	// The stored hash must never leave the server
+	if strings.Contains(r.Raw, `"password"`) || strings.Contains(r.Raw, "$2a$") {
+		t.Errorf("login response leaks the password hash: %s", r.Raw)
+	}
+	token, _ := r.Body["token"].(string)
+	s.expect(s.do("GET", "/me", token, nil), http.StatusOK, "")
+
+	s.expect(s.do("POST", "/signup", "", signup), http.StatusConflict, "email_or_phone_taken")
+}
+
+func TestSignupValidation(t *testing.T) {
+	s := newTestServer(t)
+	r := s.do("POST", "/signup", "", map[string]string{"first_name": "A", "email": "not-an-email", "password": "123"})
+	s.expect(r, http.StatusBadRequest, "validation_failed")
+	fields := map[string]bool{}
+	for _, e := range r.Body["errors"].([]any) {
+		fields[e.(map[string]any)["field"].(string)] = true
+	}
+	for _, f := range []string{"first_name", "last_name", "email", "password", "phone"} {
+		if !fields[f] {
+			t.Errorf("no error for %s: %s", f, r.Raw)
+		}
+	}
+}
+
+func TestResetPasswordWithUnknownToken(t *testing.T) {
+	s := newTestServer(t)
+	r := s.do("POST", "/auth/reset-password", "", map[string]string{"token": "no-such-token", "password": "secret123"})
+	s.expect(r, http.StatusBadRequest, "invalid_token")
+}
+
+func TestUnauthenticated(t *testing.T) {
+	s := newTestServer(t)
+	s.expect(s.do("GET", "/api/categories", "", nil), http.StatusUnauthorized, "unauthenticated")
+	s.expect(s.do("GET", "/api/categories", "not-a-token", nil), http.StatusUnauthorized, "invalid_token")
+	s.expect(s.do("GET", "/api/nowhere", "", nil), http.StatusNotFound, "route_not_found")
+}
+
+func TestUsers(t *testing.T) {
+	s := newTestServer(t)
+	userID, user := s.user(models.RoleUser)
+	otherID, _ := s.user(models.RoleUser)
+	_, admin := s.user(models.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+		code   string
+	}{
+		{"user lists users", "/users", user, http.StatusForbidden, "access_denied"},
+		{"admin lists users", "/users", admin, http.StatusOK, ""},
+		{"user reads self", "/users/" + strconv.Itoa(userID), user, http.StatusOK, ""},
+		{"user reads another", "/users/" + strconv.Itoa(otherID), user, http.StatusForbidden, "access_denied"},
+		{"admin reads another", "/users/" + strconv.Itoa(otherID), admin, http.StatusOK, ""},
+		{"admin reads nobody", "/users/999", admin, http.StatusNotFound, "user_not_found"},
+		{"bad id", "/users/abc", admin, http.StatusBadRequest, "invalid_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := s.do("GET", tt.path, tt.token, nil)
+			s.expect(r, tt.status, tt.code)
+			if strings.Contains(r.Raw, "$2a$") {
+				t.Errorf("response leaks a password hash: %s", r.Raw)
+			}
+		})
+	}
+}
+
+func TestAccountChangesSignOut(t *testing.T) {
+	s := newTestServer(t)
+
+	t.Run("suspension", func(t *testing.T) {
+		_, admin := s.user(models.RoleAdmin)
+		id, token := s.user(models.RoleUser)
+		r := s.do("POST", "/users/"+strconv.Itoa(id)+"/suspend", admin, map[string]string{"reason": "spam"})
+		s.expect(r, http.StatusOK, "")
+		s.expect(s.do("GET", "/me", token, nil), http.StatusUnauthorized, "session_revoked")
+	})
+
+	t.Run("email change", func(t *testing.T) {
+		profile := map[string]string{
+			"first_name": "Ann", "last_name": "Lee", "email": "ann@example.com", "password": "secret123", "phone": "555-0100",
+		}
+		s.expect(s.do("POST", "/signup", "", profile), http.StatusCreated, "")
+		r := s.do("POST", "/auth/verify-email", "", map[string]string{"token": s.mailToken()})
+		s.expect(r, http.StatusOK, "")
+		token, _ := r.Body["token"].(string)
+
+		delete(profile, "password")
+		profile["email"], profile["current_password"] = "ann@example.org", "secret123"
+		s.expect(s.do("PUT", "/me", token, profile), http.StatusOK, "")
+		s.mailToken()
+		s.expect(s.do("GET", "/me", token, nil), http.StatusUnauthorized, "session_revoked")
+		s.expect(s.do("POST", "/login", "", map[string]string{"email": "ann@example.org", "password": "secret123"}),
+			http.StatusForbidden, "email_unverified")
+	})
+}
+
+func TestQuestionUpdates(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.user(models.RoleUser)
+	r := s.do("POST", "/api/categories", token, map[string]string{"name": "Go"})
+	s.expect(r, http.StatusCreated, "")
+	categoryID := int(r.Body["id"].(float64))
+	r = s.do("POST", "/api/questions", token, map[string]any{
+		"category_id": categoryID, "question": "What is a goroutine?", "answer": "A green thread",
+		"context": "Concurrency", "difficulty": "Hard", "tags": []string{"concurrency"},
+	})
+	s.expect(r, http.StatusCreated, "")
+	path := "/api/questions/" + strconv.Itoa(int(r.Body["id"].(float64)))
+
+	// PUT replaces the question, so leaving fields out is refused rather
+	// than wiping them
+	r = s.do("PUT", path, token, map[string]any{"question": "What is a goroutine?"})
+	s.expect(r, http.StatusUnprocessableEntity, "incomplete_question")
+	if n := len(r.Body["errors"].([]any)); n != 4 {
+		t.Errorf("got %d field errors, want 4: %s", n, r.Raw)
+	}
+	r = s.do("PUT", path, token, map[string]any{
+		"question": "What is a goroutine?", "answer": "", "context": "", "difficulty": "easy", "tags": []string{},
+	})
+	s.expect(r, http.StatusOK, "")
+
+	r = s.do("PATCH", path, token, map[string]any{"answer": "A function running concurrently"})
+	s.expect(r, http.StatusOK, "")
+	if r.Body["difficulty"] != "Easy" || r.Body["answer"] != "A function running concurrently" {
+		t.Errorf("patched question = %s", r.Raw)
+	}
+	s.expect(s.do("PATCH", path, token, map[string]any{"difficulty": "Impossible"}), http.StatusBadRequest, "validation_failed")
+}
+
+func TestSearchHighlightsAreEscaped(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.user(models.RoleUser)
+	r := s.do("POST", "/api/categories", token, map[string]string{"name": "Web"})
+	s.expect(r, http.StatusCreated, "")
+	r = s.do("POST", "/api/questions", token, map[string]any{
+		"category_id": r.Body["id"], "question": `What does <script>alert(1)</script> do in a template?`,
+	})
+	s.expect(r, http.StatusCreated, "")
+
+	r = s.do("GET", "/api/questions/search?q=template", token, nil)
+	s.expect(r, http.StatusOK, "")
+	var results []models.SearchResult
+	if err := json.Unmarshal([]byte(r.Raw), &results); err != nil || len(results) != 1 {
+		t.Fatalf("want 1 result: %s", r.Raw)
+	}
+	highlight := results[0].Highlights.Question
+	want := "What does &lt;script&gt;alert(1)&lt;/script&gt; do in a <mark>template</mark>?"
+	if highlight != want {
+		t.Errorf("highlight = %q, want %q", highlight, want)
+	}
+}
+
+func TestCategoriesAndQuestions(t *testing.T) {
+	s := newTestServer(t)
+	_, owner := s.user(models.RoleUser)
+	_, member := s.user(models.RoleUser)
+	_, stranger := s.user(models.RoleUser)
+
+	r := s.do("POST", "/api/categories", owner, map[string]string{"name": "Go"})
+	s.expect(r, http.StatusCreated, "")
+	category := "/api/categories/" + strconv.Itoa(int(r.Body["id"].(float64)))
+	categoryID := r.Body["id"]
+	s.expect(s.do("POST", "/api/categories", stranger, map[string]string{"name": "Go"}), http.StatusConflict, "category_exists")
+
+	// member asks for access and the owner lets them in
+	s.expect(s.do("POST", category+"/request-access", member, nil), http.StatusCreated, "")
+	s.expect(s.do("POST", category+"/request-access", member, nil), http.StatusConflict, "request_exists")
+	s.expect(s.do("GET", category+"/requests", member, nil), http.StatusForbidden, "forbidden")
+	r = s.do("GET", category+"/requests", owner, nil)
+	s.expect(r, http.StatusOK, "")
+	var requests []models.AccessRequest
+	if err := json.Unmarshal([]byte(r.Raw), &requests); err != nil || len(requests) != 1 {
+		t.Fatalf("requests = %s", r.Raw)
+	}
+	respond := category + "/requests/" + strconv.Itoa(requests[0].ID) + "/respond"
+	s.expect(s.do("POST", respond, member, map[string]string{"status": "APPROVED"}), http.StatusForbidden, "forbidden")
+	s.expect(s.do("POST", respond, owner, map[string]string{"status": "APPROVED"}), http.StatusOK, "")
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		code   string
+	}{
+		{"owner", owner, http.StatusCreated, ""},
+		{"approved member", member, http.StatusCreated, ""},
+		{"stranger", stranger, http.StatusForbidden, "forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" adds a question", func(t *testing.T) {
+			r := s.do("POST", "/api/questions", tt.token, map[string]any{"category_id": categoryID, "question": "What is a goroutine?"})
+			s.expect(r, tt.status, tt.code)
+		})
+	}
+
+	r = s.do("GET", "/api/questions?category_id="+strconv.Itoa(int(categoryID.(float64))), owner, nil)
+	s.expect(r, http.StatusOK, "")
+	var page struct {
+		Items []models.Question `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(r.Raw), &page); err != nil || len(page.Items) != 2 {
+		t.Fatalf("questions = %s", r.Raw)
+	}
+	question := "/api/questions/" + strconv.Itoa(page.Items[0].ID)
+	replacement := map[string]any{"question": "What is a channel?", "answer": "", "context": "", "difficulty": "Easy", "tags": []string{}}
+	s.expect(s.do("PUT", question, owner, replacement), http.StatusOK, "")
+	s.expect(s.do("PUT", "/api/questions/999", owner, replacement), http.StatusNotFound, "question_not_found")
+	s.expect(s.do("DELETE", question, owner, nil), http.StatusOK, "")
+	s.expect(s.do("DELETE", question, owner, nil), http.StatusNotFound, "question_not_found")
+
+	s.expect(s.do("DELETE", category, stranger, nil), http.StatusForbidden, "forbidden")
+	s.expect(s.do("DELETE", category, owner, nil), http.StatusOK, "")
+	s.expect(s.do("DELETE", category, owner, nil), http.StatusNotFound, "category_not_found")
+}
+
+// problem decodes a problem details body, failing on anything else.
+func problem(t *testing.T, r response) apperr.Problem {
+	t.Helper()
+	var p apperr.Problem
+	if err := json.Unmarshal([]byte(r.Raw), &p); err != nil {
+		t.Fatalf("not problem details: %s", r.Raw)
+	}
+	return p
+}
+
+func TestErrorsAreProblemDetails(t *testing.T) {
+	s := newTestServer(t)
+	r := s.do("POST", "/signup", "", "{not json")
+	p := problem(t, r)
+	if p.Status != http.StatusBadRequest || p.Code != "invalid_body" || p.Instance != "/signup" {
+		t.Errorf("problem = %+v", p)
+	}
+}
@@ -0,0 +1,641 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory implements Store in process memory. It mirrors the Postgres
+// behaviour closely enough for handler tests and holds no data across runs.
+type Memory struct {
+	mu          sync.Mutex
+	nextID      map[string]int
+	categories  map[int]models.Category
+	questions   map[int]models.Question
+	permissions map[int]models.AccessRequest
+	users       map[int]models.User
+
+	// Trashed questions move out of questions, so nothing else sees them
+	revisions map[int][]models.QuestionRevision
+	trash     map[int]models.TrashedQuestion
+	tags      map[int]models.Tag
+
+	sessions      map[string]models.Session
+	refreshTokens map[string]memoryRefreshToken
+	accountTokens map[string]memoryAccountToken
+	identities    map[[2]string]int // issuer and subject to user id
+	oidcLogins    map[string]models.OIDCLogin
+
+	reviewStates map[[2]int]models.ReviewState
+
+	interviews map[int]models.InterviewSession
+
+	events []models.StudyEvent
+
+	auditLog []models.AuditEntry
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		nextID:      map[string]int{},
+		categories:  map[int]models.Category{},
+		questions:   map[int]models.Question{},
+		permissions: map[int]models.AccessRequest{},
+		users:       map[int]models.User{},
+
+		revisions: map[int][]models.QuestionRevision{},
+		trash:     map[int]models.TrashedQuestion{},
+		tags:      map[int]models.Tag{},
+
+		sessions:      map[string]models.Session{},
+		refreshTokens: map[string]memoryRefreshToken{},
+		accountTokens: map[string]memoryAccountToken{},
+		identities:    map[[2]string]int{},
+		oidcLogins:    map[string]models.OIDCLogin{},
+
+		reviewStates: map[[2]int]models.ReviewState{},
+
+		interviews: map[int]models.InterviewSession{},
+	}
+}
+
+// id hands out SERIAL-style identifiers per table. Callers hold m.mu.
+func (m *Memory) id(table string) int {
+	m.nextID[table]++
+	return m.nextID[table]
+}
+
+// Categories
+
+func (m *Memory) ListCategories(ctx context.Context, q models.ListQuery) ([]models.Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	categories := []models.Category{}
+	for _, cat := range m.categories {
+		if q.CreatedBy != 0 && cat.UserID != q.CreatedBy {
+			continue
+		}
+		cat.CreatorName = m.creatorName(cat.UserID)
+		cat.Role = m.categoryRole(cat.ID, q.UserID)
+		cat.HasPermission = models.RoleAtLeast(cat.Role, models.RoleContributor)
+		if q.HasPermission != nil && cat.HasPermission != *q.HasPermission {
+			continue
+		}
+		if r, ok := m.findRequest(cat.ID, q.UserID); ok {
+			cat.RequestStatus = r.Status
+		}
+		categories = append(categories, cat)
+	}
+	return pageOf(categories, q, func(c models.Category) int { return c.ID }), nil
+}
+
+func (m *Memory) GetCategory(ctx context.Context, id int) (*models.Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cat, ok := m.categories[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cat.CreatorName = m.creatorName(cat.UserID)
+	return &cat, nil
+}
+
+func (m *Memory) CreateCategory(ctx context.Context, cat *models.Category) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.categories {
+		if existing.Name == cat.Name {
+			return ErrConflict
+		}
+	}
+	cat.ID = m.id("categories")
+	cat.CreatedAt = time.Now()
+	m.categories[cat.ID] = *cat
+	return nil
+}
+
+func (m *Memory) TransferCategory(ctx context.Context, id, toUserID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cat, ok := m.categories[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, ok := m.users[toUserID]; !ok {
+		return ErrNotFound
+	}
+
+	from := cat.UserID
+	cat.UserID = toUserID
+	m.categories[id] = cat
+	if r, ok := m.findRequest(id, toUserID); ok {
+		delete(m.permissions, r.ID)
+	}
+	if from != 0 && from != toUserID {
+		m.setMemberRole(id, from, models.RoleEditor)
+	}
+	return nil
+}
+
+func (m *Memory) DeleteCategory(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[id]; !ok {
+		return ErrNotFound
+	}
+	m.deleteCategory(id)
+	return nil
+}
+
+// deleteCategory deletes a category with everything that cascades from it.
+// Callers hold m.mu.
+func (m *Memory) deleteCategory(id int) {
+	delete(m.categories, id)
+	// ON DELETE CASCADE
+	for qid, q := range m.questions {
+		if q.CategoryID == id {
+			delete(m.questions, qid)
+			delete(m.revisions, qid)
+		}
+	}
+	for qid, t := range m.trash {
+		if t.CategoryID == id {
+			delete(m.trash, qid)
+			delete(m.revisions, qid)
+		}
+	}
+	for pid, p := range m.permissions {
+		if p.CategoryID == id {
+			delete(m.permissions, pid)
+		}
+	}
+	for tid, t := range m.tags {
+		if t.CategoryID == id {
+			delete(m.tags, tid)
+		}
+	}
+}
+
+func (m *Memory) creatorName(userID int) string {
+	if u, ok := m.users[userID]; ok {
+		return u.FirstName + " " + u.LastName
+	}
+	return "Unknown"
+}
+
+// Questions
+
+func (m *Memory) ListQuestions(ctx context.Context, lq models.ListQuery) ([]models.Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var questions []models.Question
+	for _, q := range m.questions {
+		if (lq.CategoryID != 0 && q.CategoryID != lq.CategoryID) ||
+			(lq.Difficulty != "" && q.Difficulty != lq.Difficulty) ||
+			(lq.CreatedBy != 0 && q.CreatedBy != lq.CreatedBy) ||
+			!hasTags(q, lq.Tags) ||
+			(lq.UserID != 0 && !lq.Admin && m.categoryRole(q.CategoryID, lq.UserID) == "") {
+			continue
+		}
+		questions = append(questions, q)
+	}
+	return pageOf(questions, lq, func(q models.Question) int { return q.ID }), nil
+}
+
+func (m *Memory) GetQuestion(ctx context.Context, id int) (*models.Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.questions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &q, nil
+}
+
+func (m *Memory) CreateQuestion(ctx context.Context, q *models.Question) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.createQuestion(q)
+	return nil
+}
+
+func (m *Memory) ImportQuestions(ctx context.Context, questions []models.Question) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range questions {
+		if _, ok := m.categories[questions[i].CategoryID]; !ok {
+			return ErrNotFound
+		}
+	}
+	for i := range questions {
+		m.createQuestion(&questions[i])
+	}
+	return nil
+}
+
+func (m *Memory) createQuestion(q *models.Question) {
+	q.ID = m.id("questions")
+	q.CreatedAt = time.Now()
+	q.UpdatedAt = q.CreatedAt
+	q.Tags = m.useTags(q.CategoryID, q.Tags, q.CreatedBy)
+	m.questions[q.ID] = *q
+	m.addRevision(*q, q.CreatedBy, 0)
+}
+
+func (m *Memory) UpdateQuestion(ctx context.Context, q *models.Question, editorID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.questions[q.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Question = q.Question
+	existing.Answer = q.Answer
+	existing.Context = q.Context
+	existing.Difficulty = q.Difficulty
+	if q.Tags != nil {
+		existing.Tags = m.useTags(existing.CategoryID, q.Tags, editorID)
+	}
+	existing.UpdatedAt = time.Now()
+	m.questions[q.ID] = existing
+	m.addRevision(existing, editorID, 0)
+	q.CategoryID, q.Tags, q.UpdatedAt = existing.CategoryID, existing.Tags, existing.UpdatedAt
+	return nil
+}
+
+func (m *Memory) DeleteQuestion(ctx context.Context, id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.questions[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(m.questions, id)
+	m.trash[id] = models.TrashedQuestion{Question: q, DeletedAt: time.Now(), DeletedBy: userID}
+	return nil
+}
+
+// Permissions
+
+func (m *Memory) CategoryRole(ctx context.Context, categoryID, userID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[categoryID]; !ok {
+		return "", ErrNotFound
+	}
+	return m.categoryRole(categoryID, userID), nil
+}
+
+func (m *Memory) categoryRole(categoryID, userID int) string {
+	if cat, ok := m.categories[categoryID]; ok && cat.UserID == userID {
+		return models.RoleOwner
+	}
+	if r, ok := m.findRequest(categoryID, userID); ok && r.Status == "APPROVED" {
+		return r.Role
+	}
+	return ""
+}
+
+func (m *Memory) findRequest(categoryID, userID int) (models.AccessRequest, bool) {
+	for _, r := range m.permissions {
+		if r.CategoryID == categoryID && r.UserID == userID {
+			return r, true
+		}
+	}
+	return models.AccessRequest{}, false
+}
+
+func (m *Memory) CreateRequest(ctx context.Context, categoryID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.findRequest(categoryID, userID); ok {
+		return ErrConflict
+	}
+	if _, ok := m.categories[categoryID]; !ok {
+		return ErrNotFound
+	}
+	r := models.AccessRequest{
+		ID:         m.id("category_permissions"),
+		CategoryID: categoryID,
+		UserID:     userID,
+		Status:     "PENDING",
+		Role:       models.RoleContributor,
+		CreatedAt:  time.Now(),
+	}
+	m.permissions[r.ID] = r
+	return nil
+}
+
+func (m *Memory) GetRequest(ctx context.Context, id int) (*models.AccessRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.permissions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r.User = m.requestUser(r.UserID)
+	return &r, nil
+}
+
+func (m *Memory) ListPendingRequests(ctx context.Context, categoryID int) ([]models.AccessRequest, error) {
+	return m.listRequests(categoryID, "PENDING"), nil
+}
+
+func (m *Memory) ListMembers(ctx context.Context, categoryID int) ([]models.AccessRequest, error) {
+	return m.listRequests(categoryID, "APPROVED"), nil
+}
+
+func (m *Memory) listRequests(categoryID int, status string) []models.AccessRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var requests []models.AccessRequest
+	for _, r := range m.permissions {
+		if r.CategoryID == categoryID && r.Status == status {
+			r.User = m.requestUser(r.UserID)
+			requests = append(requests, r)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ID < requests[j].ID })
+	return requests
+}
+
+func (m *Memory) requestUser(userID int) models.AccessRequestUser {
+	u := m.users[userID]
+	return models.AccessRequestUser{FirstName: u.FirstName, LastName: u.LastName, Email: u.Email}
+}
+
+func (m *Memory) UpdateRequestStatus(ctx context.Context, id int, status, role string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.permissions[id]
+	if !ok {
+		return ErrNotFound
+	}
+	r.Status = status
+	if role != "" {
+		r.Role = role
+	}
+	m.permissions[id] = r
+	return nil
+}
+
+func (m *Memory) SetMemberRole(ctx context.Context, categoryID, userID int, role string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[userID]; !ok {
+		return ErrNotFound
+	}
+	m.setMemberRole(categoryID, userID, role)
+	return nil
+}
+
+func (m *Memory) setMemberRole(categoryID, userID int, role string) {
+	r, ok := m.findRequest(categoryID, userID)
+	if !ok {
+		r = models.AccessRequest{
+			ID:         m.id("category_permissions"),
+			CategoryID: categoryID,
+			UserID:     userID,
+			CreatedAt:  time.Now(),
+		}
+	}
+	r.Status = "APPROVED"
+	r.Role = role
+	m.permissions[r.ID] = r
+}
+
+func (m *Memory) RemoveMember(ctx context.Context, categoryID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.findRequest(categoryID, userID)
+	if !ok || r.Status != "APPROVED" {
+		return ErrNotFound
+	}
+	delete(m.permissions, r.ID)
+	return nil
+}
+
+// Users
+
+func (m *Memory) CreateUser(ctx context.Context, u *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Email == u.Email {
+			return ErrConflict
+		}
+	}
+	u.ID = m.id("users")
+	u.CreatedAt = time.Now()
+	u.UpdatedAt = u.CreatedAt
+	m.users[u.ID] = *u
+	return nil
+}
+
+func (m *Memory) EmailOrPhoneTaken(ctx context.Context, email, phone string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.Email == email || u.Phone == phone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *Memory) GetUser(ctx context.Context, id int) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	u.Password = ""
+	return &u, nil
+}
+
+func (m *Memory) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *Memory) ListUsers(ctx context.Context, q models.ListQuery) ([]models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	search := strings.ToLower(q.Search)
+	now := time.Now()
+	var users []models.User
+	for _, u := range m.users {
+		if search != "" && !strings.Contains(strings.ToLower(u.FirstName+" "+u.LastName), search) && !strings.Contains(strings.ToLower(u.Email), search) {
+			continue
+		}
+		if q.Role != "" && u.Role != q.Role {
+			continue
+		}
+		switch q.Status {
+		case models.UserActive:
+			if u.LockedAt != nil || u.EmailVerifiedAt == nil || u.Suspended(now) {
+				continue
+			}
+		case models.UserLocked:
+			if u.LockedAt == nil {
+				continue
+			}
+		case models.UserSuspended:
+			if !u.Suspended(now) {
+				continue
+			}
+		case models.UserUnverified:
+			if u.EmailVerifiedAt != nil {
+				continue
+			}
+		}
+		u.Password = ""
+		users = append(users, u)
+	}
+	return pageOf(users, q, func(u models.User) int { return u.ID }), nil
+}
+
+// updateUser saves u's profile, returning the user as it was. Callers hold
+// m.mu.
+func (m *Memory) updateUser(u *models.User) (*models.User, error) {
+	old, ok := m.users[u.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	for _, other := range m.users {
+		if other.ID != u.ID && (other.Email == u.Email || (u.Phone != "" && other.Phone == u.Phone)) {
+			return nil, ErrConflict
+		}
+	}
+
+	updated := old
+	updated.FirstName = u.FirstName
+	updated.LastName = u.LastName
+	updated.Email = u.Email
+	updated.Phone = u.Phone
+	updated.EmailVerifiedAt = u.EmailVerifiedAt
+	updated.UpdatedAt = time.Now()
+	m.users[u.ID] = updated
+	if old.EmailVerifiedAt != nil && u.EmailVerifiedAt == nil {
+		m.revokeSessions(u.ID)
+	}
+
+	*u = updated
+	u.Password = ""
+	old.Password = ""
+	return &old, nil
+}
+
+func (m *Memory) UpdateUser(ctx context.Context, u *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.updateUser(u)
+	return err
+}
+
+func (m *Memory) ChangePassword(ctx context.Context, userID int, passwordHash, keepSessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	u.Password = passwordHash
+	u.UpdatedAt = time.Now()
+	m.users[userID] = u
+
+	now := time.Now()
+	for id, s := range m.sessions {
+		if s.UserID == userID && id != keepSessionID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+			m.sessions[id] = s
+		}
+	}
+	return nil
+}
+
+func (m *Memory) UserSummary(ctx context.Context, userID int) (*models.UserSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	u.Password = ""
+	summary := models.UserSummary{User: u, OwnedCategories: []models.OwnedCategory{}, Permissions: []models.UserPermission{}}
+
+	for _, cat := range m.categories {
+		if cat.UserID != userID {
+			continue
+		}
+		owned := models.OwnedCategory{ID: cat.ID, Name: cat.Name, CreatedAt: cat.CreatedAt}
+		for _, q := range m.questions {
+			if q.CategoryID == cat.ID {
+				owned.QuestionCount++
+			}
+		}
+		for _, p := range m.permissions {
+			if p.CategoryID == cat.ID && p.Status == "APPROVED" {
+				owned.MemberCount++
+			}
+		}
+		summary.OwnedCategories = append(summary.OwnedCategories, owned)
+	}
+	for _, p := range m.permissions {
+		if p.UserID == userID {
+			summary.Permissions = append(summary.Permissions, models.UserPermission{
+				CategoryID:   p.CategoryID,
+				CategoryName: m.categories[p.CategoryID].Name,
+				Status:       p.Status,
+				Role:         p.Role,
+				CreatedAt:    p.CreatedAt,
+			})
+		}
+	}
+	sort.Slice(summary.OwnedCategories, func(i, j int) bool {
+		return summary.OwnedCategories[i].Name < summary.OwnedCategories[j].Name
+	})
+	sort.Slice(summary.Permissions, func(i, j int) bool {
+		return summary.Permissions[i].CategoryName < summary.Permissions[j].CategoryName
+	})
+	return &summary, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"time"
+)
+
+type memoryAccountToken struct {
+	UserID    int
+	Purpose   string
+	ExpiresAt time.Time
+	Used      bool
+}
+
+func (m *Memory) CreateAccountToken(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for hash, t := range m.accountTokens {
+		if t.UserID == userID && t.Purpose == purpose && !t.Used {
+			delete(m.accountTokens, hash)
+		}
+	}
+	m.accountTokens[tokenHash] = memoryAccountToken{UserID: userID, Purpose: purpose, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (m *Memory) CheckAccountToken(ctx context.Context, purpose, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.accountTokens[tokenHash]
+	if !ok || t.Purpose != purpose || t.Used || !t.ExpiresAt.After(time.Now()) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// consumeAccountToken marks a live token used and returns its user.
+// Callers hold m.mu.
+func (m *Memory) consumeAccountToken(purpose, tokenHash string) (*models.User, error) {
+	t, ok := m.accountTokens[tokenHash]
+	if !ok || t.Purpose != purpose || t.Used || !t.ExpiresAt.After(time.Now()) {
+		return nil, ErrNotFound
+	}
+	u, ok := m.users[t.UserID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t.Used = true
+	m.accountTokens[tokenHash] = t
+	if u.EmailVerifiedAt == nil {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+	return &u, nil
+}
+
+func (m *Memory) VerifyEmail(ctx context.Context, tokenHash string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, err := m.consumeAccountToken(models.TokenVerifyEmail, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	m.users[u.ID] = *u
+	u.Password = ""
+	return u, nil
+}
+
+func (m *Memory) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, err := m.consumeAccountToken(models.TokenResetPassword, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	u.Password = passwordHash
+	u.PasswordResetRequired = false
+	u.UpdatedAt = time.Now()
+	m.users[u.ID] = *u
+	m.revokeSessions(u.ID)
+	u.Password = ""
+	return u, nil
+}
@@ -0,0 +1,290 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"sort"
+	"time"
+)
+
+// audit appends an entry to the audit log. Callers hold m.mu.
+func (m *Memory) audit(actorID int, action string, targetID int, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	e := models.AuditEntry{ID: m.id("audit_log"), Action: action, Details: details, CreatedAt: time.Now()}
+	if actorID != 0 {
+		e.ActorID = &actorID
+	}
+	if targetID != 0 {
+		e.TargetUserID = &targetID
+	}
+	m.auditLog = append(m.auditLog, e)
+}
+
+// revokeSessions ends every session of userID. Callers hold m.mu.
+func (m *Memory) revokeSessions(userID int) {
+	now := time.Now()
+	for id, s := range m.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+			m.sessions[id] = s
+		}
+	}
+}
+
+func (m *Memory) CreateAdmin(ctx context.Context, actorID int, u *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Email == u.Email {
+			return ErrConflict
+		}
+	}
+	now := time.Now()
+	u.ID = m.id("users")
+	u.Role = models.RoleAdmin
+	u.EmailVerifiedAt = &now
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	m.users[u.ID] = *u
+	m.audit(actorID, models.AuditAdminCreated, u.ID, map[string]any{"email": u.Email})
+	return nil
+}
+
+func (m *Memory) SetUserRole(ctx context.Context, actorID, userID int, role string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	from := u.Role
+	if from == role {
+		return nil
+	}
+	if from == models.RoleAdmin {
+		others := 0
+		for _, other := range m.users {
+			if other.Role == models.RoleAdmin && other.ID != userID {
+				others++
+			}
+		}
+		if others == 0 {
+			return ErrLastAdmin
+		}
+	}
+
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	m.users[userID] = u
+	m.revokeSessions(userID)
+	m.audit(actorID, models.AuditRoleChanged, userID, map[string]any{"from": from, "to": role})
+	return nil
+}
+
+func (m *Memory) SetUserLocked(ctx context.Context, actorID, userID int, locked bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	action := models.AuditUserUnlocked
+	if locked {
+		action = models.AuditUserLocked
+		if u.LockedAt == nil {
+			now := time.Now()
+			u.LockedAt = &now
+		}
+		m.revokeSessions(userID)
+	} else {
+		u.LockedAt = nil
+	}
+	m.users[userID] = u
+	m.audit(actorID, action, userID, nil)
+	return nil
+}
+
+func (m *Memory) RequirePasswordReset(ctx context.Context, actorID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	u.PasswordResetRequired = true
+	m.users[userID] = u
+	m.revokeSessions(userID)
+	m.audit(actorID, models.AuditPasswordResetForced, userID, nil)
+	return nil
+}
+
+func (m *Memory) ListAuditLog(ctx context.Context, q models.ListQuery) ([]models.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var entries []models.AuditEntry
+	for _, e := range m.auditLog {
+		if q.TargetUserID != 0 && (e.TargetUserID == nil || *e.TargetUserID != q.TargetUserID) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return pageOf(entries, q, func(e models.AuditEntry) int { return e.ID }), nil
+}
+
+func (m *Memory) EditUser(ctx context.Context, actorID int, u *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, err := m.updateUser(u)
+	if err != nil {
+		return err
+	}
+	m.audit(actorID, models.AuditUserUpdated, u.ID, profileChanges(old, u))
+	return nil
+}
+
+func (m *Memory) SuspendUser(ctx context.Context, actorID, userID int, until *time.Time, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	u.SuspendedAt = &now
+	u.SuspendedUntil = until
+	u.SuspensionReason = reason
+	m.users[userID] = u
+	m.revokeSessions(userID)
+	m.audit(actorID, models.AuditUserSuspended, userID, map[string]any{"until": until, "reason": reason})
+	return nil
+}
+
+func (m *Memory) UnsuspendUser(ctx context.Context, actorID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	u.SuspendedAt = nil
+	u.SuspendedUntil = nil
+	u.SuspensionReason = ""
+	m.users[userID] = u
+	m.audit(actorID, models.AuditUserUnsuspended, userID, nil)
+	return nil
+}
+
+func (m *Memory) DeleteUser(ctx context.Context, actorID, userID, reassignTo int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	if u.Role == models.RoleAdmin {
+		others := 0
+		for _, other := range m.users {
+			if other.Role == models.RoleAdmin && other.ID != userID {
+				others++
+			}
+		}
+		if others == 0 {
+			return ErrLastAdmin
+		}
+	}
+	if _, ok := m.users[reassignTo]; reassignTo != 0 && !ok {
+		return ErrNotFound
+	}
+
+	details := map[string]any{"user_id": userID, "email": u.Email}
+	categoryIDs := []int64{}
+	for id, cat := range m.categories {
+		if cat.UserID != userID {
+			continue
+		}
+		categoryIDs = append(categoryIDs, int64(id))
+		if reassignTo != 0 {
+			cat.UserID = reassignTo
+			m.categories[id] = cat
+			if r, ok := m.findRequest(id, reassignTo); ok {
+				delete(m.permissions, r.ID)
+			}
+		} else {
+			m.deleteCategory(id)
+		}
+	}
+	sort.Slice(categoryIDs, func(i, j int) bool { return categoryIDs[i] < categoryIDs[j] })
+	if reassignTo != 0 {
+		details["reassigned_to"] = reassignTo
+		details["reassigned_categories"] = categoryIDs
+	} else {
+		details["deleted_categories"] = categoryIDs
+	}
+	m.audit(actorID, models.AuditUserDeleted, userID, details)
+
+	// ON DELETE CASCADE and SET NULL
+	delete(m.users, userID)
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	for id, p := range m.permissions {
+		if p.UserID == userID {
+			delete(m.permissions, id)
+		}
+	}
+	for key, id := range m.identities {
+		if id == userID {
+			delete(m.identities, key)
+		}
+	}
+	for hash, t := range m.accountTokens {
+		if t.UserID == userID {
+			delete(m.accountTokens, hash)
+		}
+	}
+	events := m.events[:0]
+	for _, e := range m.events {
+		if e.UserID != userID {
+			events = append(events, e)
+		}
+	}
+	m.events = events
+	for key := range m.reviewStates {
+		if key[0] == userID {
+			delete(m.reviewStates, key)
+		}
+	}
+	for id, s := range m.interviews {
+		if s.UserID == userID {
+			delete(m.interviews, id)
+		}
+	}
+	for id, q := range m.questions {
+		if q.CreatedBy == userID {
+			q.CreatedBy = 0
+			m.questions[id] = q
+		}
+	}
+	for i, e := range m.auditLog {
+		if e.ActorID != nil && *e.ActorID == userID {
+			m.auditLog[i].ActorID = nil
+		}
+		if e.TargetUserID != nil && *e.TargetUserID == userID {
+			m.auditLog[i].TargetUserID = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"time"
+)
+
+func (m *Memory) SaveOIDCLogin(ctx context.Context, l *models.OIDCLogin) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for hash, old := range m.oidcLogins {
+		if !old.ExpiresAt.After(now) {
+			delete(m.oidcLogins, hash)
+		}
+	}
+	m.oidcLogins[l.StateHash] = *l
+	return nil
+}
+
+func (m *Memory) TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.oidcLogins[stateHash]
+	if !ok || !l.ExpiresAt.After(time.Now()) {
+		return nil, ErrNotFound
+	}
+	delete(m.oidcLogins, stateHash)
+	return &l, nil
+}
+
+func (m *Memory) SignInWithIdentity(ctx context.Context, id models.ExternalIdentity) (*models.User, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{id.Issuer, id.Subject}
+	userID, linked := m.identities[key]
+	created := false
+	if !linked {
+		for _, u := range m.users {
+			if u.Email == id.Email {
+				userID = u.ID
+			}
+		}
+		now := time.Now()
+		switch {
+		case userID == 0:
+			created = true
+			u := models.User{
+				ID:        m.id("users"),
+				FirstName: id.FirstName,
+				LastName:  id.LastName,
+				Email:     id.Email,
+				Password:  unusablePassword,
+				Role:      models.RoleUser,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if id.EmailVerified {
+				u.EmailVerifiedAt = &now
+			}
+			m.users[u.ID] = u
+			userID = u.ID
+		case !id.EmailVerified:
+			return nil, false, ErrConflict
+		default:
+			if u := m.users[userID]; u.EmailVerifiedAt == nil {
+				u.Password = unusablePassword
+				u.EmailVerifiedAt = &now
+				u.UpdatedAt = now
+				m.users[userID] = u
+				m.revokeSessions(userID)
+			}
+		}
+		m.identities[key] = userID
+	}
+
+	u := m.users[userID]
+	u.Password = ""
+	return &u, created, nil
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+)
+
+func (m *Memory) SampleQuestions(ctx context.Context, categoryIDs []int, difficulty string, limit int, exclude []int) ([]models.Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var candidates []models.Question
+	for _, q := range m.questions {
+		if !slices.Contains(categoryIDs, q.CategoryID) || slices.Contains(exclude, q.ID) {
+			continue
+		}
+		if difficulty != "" && q.Difficulty != difficulty {
+			continue
+		}
+		candidates = append(candidates, q)
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+func (m *Memory) CreateInterview(ctx context.Context, s *models.InterviewSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.ID = m.id("interview_sessions")
+	s.CreatedAt = time.Now()
+	for i := range s.Questions {
+		s.Questions[i].ID = m.id("interview_questions")
+		s.Questions[i].SessionID = s.ID
+	}
+	stored := *s
+	stored.Questions = slices.Clone(s.Questions)
+	m.interviews[s.ID] = stored
+	return nil
+}
+
+func (m *Memory) ListInterviews(ctx context.Context, userID int) ([]models.InterviewSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessions := []models.InterviewSession{}
+	for _, s := range m.interviews {
+		if s.UserID == userID {
+			s.Questions = nil
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID > sessions[j].ID })
+	return sessions, nil
+}
+
+func (m *Memory) GetInterview(ctx context.Context, id int) (*models.InterviewSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.interviews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.Questions = slices.Clone(s.Questions)
+	return &s, nil
+}
+
+// interviewQuestion finds a stored interview question by id. Callers hold m.mu.
+func (m *Memory) interviewQuestion(id int) (*models.InterviewQuestion, bool) {
+	for _, s := range m.interviews {
+		for i := range s.Questions {
+			if s.Questions[i].ID == id {
+				return &s.Questions[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (m *Memory) MarkInterviewQuestionServed(ctx context.Context, id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.interviewQuestion(id)
+	if !ok || q.ServedAt != nil {
+		return ErrNotFound
+	}
+	q.ServedAt = &at
+	return nil
+}
+
+func (m *Memory) SaveInterviewResponse(ctx context.Context, resp *models.InterviewQuestion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.interviewQuestion(resp.ID)
+	if !ok || q.AnsweredAt != nil {
+		return ErrNotFound
+	}
+	q.AnsweredAt = resp.AnsweredAt
+	q.Response = resp.Response
+	q.TimeTakenMs = resp.TimeTakenMs
+	q.SelfRating = resp.SelfRating
+	q.TimedOut = resp.TimedOut
+	return nil
+}
+
+func (m *Memory) CompleteInterview(ctx context.Context, id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.interviews[id]
+	if !ok || s.Status != models.InterviewInProgress {
+		return ErrNotFound
+	}
+	s.Status = models.InterviewCompleted
+	s.CompletedAt = &at
+	m.interviews[id] = s
+	return nil
+}
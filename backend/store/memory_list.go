@@ -0,0 +1,64 @@
+package store
+
+import (
+	"interview-prep/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var difficultyRanks = map[string]int{"Easy": 1, "Medium": 2, "Hard": 3}
+
+// pageOf orders items the way the Postgres keyset queries do and returns
+// the ones past the cursor, up to Limit+1.
+func pageOf[T interface{ SortValue(string) string }](items []T, q models.ListQuery, id func(T) int) []T {
+	less := func(av string, aid int, bv string, bid int) bool {
+		if av != bv {
+			return av < bv
+		}
+		return aid < bid
+	}
+	before := func(av string, aid int, bv string, bid int) bool {
+		if q.Desc {
+			return less(bv, bid, av, aid)
+		}
+		return less(av, aid, bv, bid)
+	}
+
+	keys := make(map[int]string, len(items))
+	for _, item := range items {
+		keys[id(item)] = memorySortKey(q.Sort, item.SortValue(q.Sort))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := id(items[i]), id(items[j])
+		return before(keys[a], a, keys[b], b)
+	})
+
+	if q.After != nil {
+		after := memorySortKey(q.Sort, q.After.Value)
+		start := sort.Search(len(items), func(i int) bool {
+			return before(after, q.After.ID, keys[id(items[i])], id(items[i]))
+		})
+		items = items[start:]
+	}
+	if q.Limit > 0 && len(items) > q.Limit+1 {
+		items = items[:q.Limit+1]
+	}
+	return items
+}
+
+// memorySortKey mirrors the sortExpr formats: difficulties order by rank
+// and names ignore case. Timestamps already order as strings.
+func memorySortKey(key, value string) string {
+	switch key {
+	case models.SortDifficulty:
+		rank, ok := difficultyRanks[value]
+		if !ok {
+			rank = len(difficultyRanks) + 1
+		}
+		return strconv.Itoa(rank)
+	case models.SortName:
+		return strings.ToLower(value)
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"sort"
+	"time"
+)
+
+func (m *Memory) ListDueReviews(ctx context.Context, userID, categoryID int, now time.Time, limit int) ([]models.DueReview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := []models.DueReview{}
+	for _, q := range m.questions {
+		if categoryID != 0 && q.CategoryID != categoryID {
+			continue
+		}
+		if m.categoryRole(q.CategoryID, userID) == "" {
+			continue
+		}
+		d := models.DueReview{Question: q}
+		if s, ok := m.reviewStates[[2]int{userID, q.ID}]; ok {
+			if s.DueAt.After(now) {
+				continue
+			}
+			d.Review = &s
+		}
+		due = append(due, d)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		if (a.Review == nil) != (b.Review == nil) {
+			return a.Review != nil
+		}
+		if a.Review != nil && !a.Review.DueAt.Equal(b.Review.DueAt) {
+			return a.Review.DueAt.Before(b.Review.DueAt)
+		}
+		return a.Question.CreatedAt.Before(b.Question.CreatedAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *Memory) GetReviewState(ctx context.Context, userID, questionID int) (*models.ReviewState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.reviewStates[[2]int{userID, questionID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *Memory) SaveReviewState(ctx context.Context, s *models.ReviewState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reviewStates[[2]int{s.UserID, s.QuestionID}] = *s
+	return nil
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"slices"
+	"sort"
+	"time"
+)
+
+// addRevision records q's current content as its next revision. Callers
+// hold m.mu.
+func (m *Memory) addRevision(q models.Question, editorID, restoredFrom int) {
+	m.revisions[q.ID] = append(m.revisions[q.ID], models.QuestionRevision{
+		QuestionID:   q.ID,
+		Revision:     len(m.revisions[q.ID]) + 1,
+		Question:     q.Question,
+		Answer:       q.Answer,
+		Context:      q.Context,
+		Difficulty:   q.Difficulty,
+		EditedBy:     editorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (m *Memory) ListRevisions(ctx context.Context, questionID int) ([]models.QuestionRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions := []models.QuestionRevision{}
+	for _, r := range m.revisions[questionID] {
+		r.EditorName = m.creatorName(r.EditedBy)
+		revisions = append(revisions, r)
+	}
+	slices.Reverse(revisions)
+	return revisions, nil
+}
+
+func (m *Memory) RestoreRevision(ctx context.Context, questionID, rev, userID int) (*models.Question, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.questions[questionID]
+	if !ok || rev < 1 || rev > len(m.revisions[questionID]) {
+		return nil, ErrNotFound
+	}
+	r := m.revisions[questionID][rev-1]
+	q.Question, q.Answer, q.Context, q.Difficulty = r.Question, r.Answer, r.Context, r.Difficulty
+	q.UpdatedAt = time.Now()
+	m.questions[questionID] = q
+	m.addRevision(q, userID, rev)
+	return &q, nil
+}
+
+// Trash
+
+func (m *Memory) ListTrash(ctx context.Context, userID, categoryID int, since time.Time) ([]models.TrashedQuestion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trash := []models.TrashedQuestion{}
+	for _, t := range m.trash {
+		if t.DeletedAt.Before(since) ||
+			(userID != 0 && m.categoryRole(t.CategoryID, userID) == "") ||
+			(categoryID != 0 && t.CategoryID != categoryID) {
+			continue
+		}
+		trash = append(trash, t)
+	}
+	sort.Slice(trash, func(i, j int) bool {
+		if !trash[i].DeletedAt.Equal(trash[j].DeletedAt) {
+			return trash[i].DeletedAt.After(trash[j].DeletedAt)
+		}
+		return trash[i].ID > trash[j].ID
+	})
+	return trash, nil
+}
+
+func (m *Memory) GetTrashedQuestion(ctx context.Context, id int, since time.Time) (*models.TrashedQuestion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.trash[id]
+	if !ok || t.DeletedAt.Before(since) {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m *Memory) RestoreQuestion(ctx context.Context, id int, since time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.trash[id]
+	if !ok || t.DeletedAt.Before(since) {
+		return ErrNotFound
+	}
+	delete(m.trash, id)
+	m.questions[id] = t.Question
+	return nil
+}
+
+func (m *Memory) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for id, t := range m.trash {
+		if t.DeletedAt.Before(before) {
+			delete(m.trash, id)
+			delete(m.revisions, id)
+			n++
+		}
+	}
+	return n, nil
+}
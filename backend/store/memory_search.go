@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"html"
+	"interview-prep/models"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// searchWeights mirror the default ts_rank weights for the A (question),
+// B (answer) and C (context) parts of the search vector.
+var searchWeights = [3]float64{1.0, 0.4, 0.2}
+
+var searchWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchStopWords are the most common entries of Postgres' english stop
+// word list, which never match anything.
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "how": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true, "what": true,
+	"when": true, "where": true, "which": true, "who": true, "why": true, "with": true,
+}
+
+// SearchQuestions approximates websearch_to_tsquery: every term must match
+// (terms prefixed with "-" must not), matches in the question outrank
+// matches in the answer, which outrank the context.
+func (m *Memory) SearchQuestions(ctx context.Context, search models.QuestionSearch) ([]models.SearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	include, exclude := parseSearchQuery(search.Query)
+	results := []models.SearchResult{}
+	if len(include) == 0 {
+		return results, nil
+	}
+
+	for _, q := range m.questions {
+		if !search.Admin && m.categoryRole(q.CategoryID, search.UserID) == "" {
+			continue
+		}
+		if (search.CategoryID != 0 && q.CategoryID != search.CategoryID) ||
+			(search.Difficulty != "" && q.Difficulty != search.Difficulty) ||
+			(search.CreatedBy != 0 && q.CreatedBy != search.CreatedBy) {
+			continue
+		}
+
+		fields := [3]map[string]int{termCounts(q.Question), termCounts(q.Answer), termCounts(q.Context)}
+		rank, matched := 0.0, true
+		for _, term := range include {
+			found := false
+			for i, counts := range fields {
+				if n := counts[term]; n > 0 {
+					rank += searchWeights[i] * float64(n)
+					found = true
+				}
+			}
+			if !found {
+				matched = false
+				break
+			}
+		}
+		for _, term := range exclude {
+			for _, counts := range fields {
+				if counts[term] > 0 {
+					matched = false
+				}
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Question: q,
+			Rank:     rank,
+			Highlights: models.SearchHighlight{
+				Question: highlightTerms(q.Question, include),
+				Answer:   highlightTerms(q.Answer, include),
+				Context:  highlightTerms(q.Context, include),
+			},
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Question.ID > results[j].Question.ID
+	})
+	if search.Limit > 0 && len(results) > search.Limit {
+		results = results[:search.Limit]
+	}
+	return results, nil
+}
+
+func parseSearchQuery(query string) (include, exclude []string) {
+	for _, field := range strings.Fields(query) {
+		negate := strings.HasPrefix(field, "-")
+		for _, word := range searchWordPattern.FindAllString(field, -1) {
+			term := normalizeSearchTerm(word)
+			if term == "" {
+				continue
+			}
+			if negate {
+				exclude = append(exclude, term)
+			} else {
+				include = append(include, term)
+			}
+		}
+	}
+	return include, exclude
+}
+
+func termCounts(text string) map[string]int {
+	counts := map[string]int{}
+	for _, word := range searchWordPattern.FindAllString(text, -1) {
+		if term := normalizeSearchTerm(word); term != "" {
+			counts[term]++
+		}
+	}
+	return counts
+}
+
+// normalizeSearchTerm lowercases word, drops stop words and strips common
+// English suffixes, a rough stand-in for the snowball stemmer.
+func normalizeSearchTerm(word string) string {
+	word = strings.ToLower(word)
+	if searchStopWords[word] {
+		return ""
+	}
+	for _, suffix := range []string{"ing", "ies", "ed", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, suffix)
+			if suffix == "ies" {
+				word += "y"
+			}
+			break
+		}
+	}
+	return word
+}
+
+// highlightTerms HTML-escapes text and wraps the words matching terms in
+// <mark> tags.
+func highlightTerms(text string, terms []string) string {
+	wanted := map[string]bool{}
+	for _, t := range terms {
+		wanted[t] = true
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range searchWordPattern.FindAllStringIndex(text, -1) {
+		word := text[loc[0]:loc[1]]
+		if !wanted[normalizeSearchTerm(word)] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"time"
+)
+
+type memoryRefreshToken struct {
+	SessionID string
+	ExpiresAt time.Time
+	Used      bool
+}
+
+func (m *Memory) CreateSession(ctx context.Context, s *models.Session, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.CreatedAt = time.Now()
+	m.sessions[s.ID] = *s
+	m.refreshTokens[tokenHash] = memoryRefreshToken{SessionID: s.ID, ExpiresAt: s.ExpiresAt}
+	return nil
+}
+
+func (m *Memory) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rt, ok := m.refreshTokens[oldHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s := m.sessions[rt.SessionID]
+
+	now := time.Now()
+	if s.RevokedAt != nil || !s.ExpiresAt.After(now) || !rt.ExpiresAt.After(now) {
+		return nil, ErrSessionInactive
+	}
+	if u := m.users[s.UserID]; u.Suspended(now) {
+		return nil, ErrSuspended
+	}
+	if rt.Used {
+		s.RevokedAt = &now
+		m.sessions[s.ID] = s
+		return nil, ErrRefreshTokenReused
+	}
+
+	rt.Used = true
+	m.refreshTokens[oldHash] = rt
+	m.refreshTokens[newHash] = memoryRefreshToken{SessionID: s.ID, ExpiresAt: expiresAt}
+	s.ExpiresAt = expiresAt
+	m.sessions[s.ID] = s
+	return &s, nil
+}
+
+func (m *Memory) SessionActive(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	now := time.Now()
+	if !ok || s.RevokedAt != nil || !s.ExpiresAt.After(now) {
+		return false, nil
+	}
+	if u := m.users[s.UserID]; u.Suspended(now) {
+		return false, ErrSuspended
+	}
+	return true, nil
+}
+
+func (m *Memory) RevokeSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.RevokedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	m.sessions[id] = s
+	return nil
+}
+
+func (m *Memory) RevokeUserSessions(ctx context.Context, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range m.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+			m.sessions[id] = s
+		}
+	}
+	return nil
+}
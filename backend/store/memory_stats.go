@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"sort"
+	"time"
+)
+
+// The memory store has no rollups; each stat is computed from the log.
+
+func (m *Memory) RecordEvent(ctx context.Context, e *models.StudyEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.ID = m.id("study_events")
+	e.CreatedAt = time.Now()
+	m.events = append(m.events, *e)
+	return nil
+}
+
+// activeDays returns the distinct days userID has events on, oldest first.
+// Callers hold m.mu.
+func (m *Memory) activeDays(userID int) []time.Time {
+	seen := map[time.Time]bool{}
+	var days []time.Time
+	for _, e := range m.events {
+		day := models.BucketStart(e.CreatedAt, models.BucketDay)
+		if e.UserID == userID && !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func (m *Memory) ActivityTotals(ctx context.Context, userID int) (*models.ActivityTotals, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := models.ActivityTotals{ActiveDays: len(m.activeDays(userID))}
+	for _, e := range m.events {
+		if e.UserID != userID {
+			continue
+		}
+		switch e.Type {
+		case models.EventViewed:
+			t.Viewed++
+		case models.EventAnswered:
+			t.Answered++
+		case models.EventGraded:
+			t.Graded++
+		case models.EventInterviewCompleted:
+			t.InterviewsCompleted++
+		}
+	}
+	return &t, nil
+}
+
+func (m *Memory) Streak(ctx context.Context, userID int, today time.Time) (*models.Streak, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var s models.Streak
+	days := m.activeDays(userID)
+	run := 0
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1].AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		s.Longest = max(s.Longest, run)
+	}
+	if len(days) > 0 {
+		last := days[len(days)-1]
+		s.LastActive = &last
+		if !last.Before(models.BucketStart(today, models.BucketDay).AddDate(0, 0, -1)) {
+			s.Current = run
+		}
+	}
+	return &s, nil
+}
+
+func (m *Memory) Mastery(ctx context.Context, userID, matureDays int) ([]models.MasteryStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type key struct {
+		categoryID int
+		difficulty string
+	}
+	groups := map[key]*models.MasteryStats{}
+	for k, s := range m.reviewStates {
+		q, ok := m.questions[k[1]]
+		if k[0] != userID || !ok {
+			continue
+		}
+		g := groups[key{q.CategoryID, q.Difficulty}]
+		if g == nil {
+			g = &models.MasteryStats{CategoryID: q.CategoryID, CategoryName: m.categories[q.CategoryID].Name, Difficulty: q.Difficulty}
+			groups[key{q.CategoryID, q.Difficulty}] = g
+		}
+		g.Reviewed++
+		if s.IntervalDays >= matureDays {
+			g.Mastered++
+		}
+	}
+
+	mastery := []models.MasteryStats{}
+	for _, g := range groups {
+		mastery = append(mastery, *g)
+	}
+	sort.Slice(mastery, func(i, j int) bool {
+		if mastery[i].CategoryName != mastery[j].CategoryName {
+			return mastery[i].CategoryName < mastery[j].CategoryName
+		}
+		return mastery[i].Difficulty < mastery[j].Difficulty
+	})
+	return mastery, nil
+}
+
+func (m *Memory) Accuracy(ctx context.Context, userID int, bucket string, since time.Time) ([]models.AccuracyBucket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sinceDay := models.BucketStart(since, models.BucketDay)
+	groups := map[time.Time]*models.AccuracyBucket{}
+	for _, e := range m.events {
+		if e.UserID != userID || e.Correct == nil || e.CreatedAt.Before(sinceDay) {
+			continue
+		}
+		start := models.BucketStart(e.CreatedAt, bucket)
+		b := groups[start]
+		if b == nil {
+			b = &models.AccuracyBucket{Start: start}
+			groups[start] = b
+		}
+		b.Attempts++
+		if *e.Correct {
+			b.Correct++
+		}
+	}
+
+	buckets := []models.AccuracyBucket{}
+	for _, b := range groups {
+		b.Accuracy = float64(b.Correct) / float64(b.Attempts)
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+func (m *Memory) WeakestCategories(ctx context.Context, userID, minAttempts, limit int) ([]models.CategoryAccuracy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	groups := map[int]*models.CategoryAccuracy{}
+	for _, e := range m.events {
+		cat, ok := m.categories[e.CategoryID]
+		if e.UserID != userID || e.Correct == nil || !ok {
+			continue
+		}
+		a := groups[cat.ID]
+		if a == nil {
+			a = &models.CategoryAccuracy{CategoryID: cat.ID, CategoryName: cat.Name}
+			groups[cat.ID] = a
+		}
+		a.Attempts++
+		if *e.Correct {
+			a.Correct++
+		}
+	}
+
+	weakest := []models.CategoryAccuracy{}
+	for _, a := range groups {
+		if a.Attempts < max(minAttempts, 1) {
+			continue
+		}
+		a.Accuracy = float64(a.Correct) / float64(a.Attempts)
+		weakest = append(weakest, *a)
+	}
+	sort.Slice(weakest, func(i, j int) bool {
+		if weakest[i].Accuracy != weakest[j].Accuracy {
+			return weakest[i].Accuracy < weakest[j].Accuracy
+		}
+		if weakest[i].Attempts != weakest[j].Attempts {
+			return weakest[i].Attempts > weakest[j].Attempts
+		}
+		return weakest[i].CategoryID < weakest[j].CategoryID
+	})
+	if len(weakest) > limit {
+		weakest = weakest[:limit]
+	}
+	return weakest, nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// useTags creates the tags in names that the category doesn't have yet and
+// returns a sorted copy of names for a question to keep. Callers hold m.mu.
+func (m *Memory) useTags(categoryID int, names []string, userID int) []string {
+	names = slices.Clone(names)
+	for _, name := range names {
+		if _, ok := m.findTag(categoryID, name); !ok {
+			id := m.id("tags")
+			m.tags[id] = models.Tag{ID: id, CategoryID: categoryID, Name: name, CreatedBy: userID, CreatedAt: time.Now()}
+		}
+	}
+	sort.Strings(names)
+	if names == nil {
+		names = []string{}
+	}
+	return names
+}
+
+func (m *Memory) findTag(categoryID int, name string) (models.Tag, bool) {
+	for _, t := range m.tags {
+		if t.CategoryID == categoryID && t.Name == name {
+			return t, true
+		}
+	}
+	return models.Tag{}, false
+}
+
+func hasTags(q models.Question, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(q.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// retag rewrites the tag old to new on every question of the category,
+// trashed ones included; an empty new removes it. Callers hold m.mu.
+func (m *Memory) retag(categoryID int, old, new string) {
+	replace := func(q models.Question) models.Question {
+		if q.CategoryID != categoryID || !slices.Contains(q.Tags, old) {
+			return q
+		}
+		tags := slices.DeleteFunc(slices.Clone(q.Tags), func(t string) bool { return t == old })
+		if new != "" {
+			tags = append(tags, new)
+			sort.Strings(tags)
+		}
+		q.Tags = tags
+		return q
+	}
+	for id, q := range m.questions {
+		m.questions[id] = replace(q)
+	}
+	for id, t := range m.trash {
+		t.Question = replace(t.Question)
+		m.trash[id] = t
+	}
+}
+
+func (m *Memory) countTagged(t models.Tag) int {
+	n := 0
+	for _, q := range m.questions {
+		if q.CategoryID == t.CategoryID && slices.Contains(q.Tags, t.Name) {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Memory) ListTags(ctx context.Context, q models.TagQuery) ([]models.Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tags := []models.Tag{}
+	for _, t := range m.tags {
+		if (q.UserID != 0 && m.categoryRole(t.CategoryID, q.UserID) == "") ||
+			(q.CategoryID != 0 && t.CategoryID != q.CategoryID) ||
+			!strings.HasPrefix(t.Name, q.Prefix) {
+			continue
+		}
+		t.QuestionCount = m.countTagged(t)
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Name != tags[j].Name {
+			return tags[i].Name < tags[j].Name
+		}
+		return tags[i].CategoryID < tags[j].CategoryID
+	})
+	return tags, nil
+}
+
+func (m *Memory) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tags[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t.QuestionCount = m.countTagged(t)
+	return &t, nil
+}
+
+func (m *Memory) CreateTag(ctx context.Context, t *models.Tag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[t.CategoryID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := m.findTag(t.CategoryID, t.Name); ok {
+		return ErrConflict
+	}
+	t.ID = m.id("tags")
+	t.CreatedAt = time.Now()
+	m.tags[t.ID] = *t
+	return nil
+}
+
+func (m *Memory) RenameTag(ctx context.Context, id int, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tags[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if other, ok := m.findTag(t.CategoryID, name); ok && other.ID != id {
+		return ErrConflict
+	}
+	m.retag(t.CategoryID, t.Name, name)
+	t.Name = name
+	m.tags[id] = t
+	return nil
+}
+
+func (m *Memory) DeleteTag(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tags[id]
+	if !ok {
+		return ErrNotFound
+	}
+	m.retag(t.CategoryID, t.Name, "")
+	delete(m.tags, id)
+	return nil
+}
@@ -0,0 +1,700 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"interview-prep/models"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Postgres implements Store on top of a *sql.DB.
+type Postgres struct {
+	DB *sql.DB
+}
+
+var _ Store = (*Postgres)(nil)
+
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{DB: db}
+}
+
+// Categories
+
+func (p *Postgres) ListCategories(ctx context.Context, q models.ListQuery) ([]models.Category, error) {
+	var b listBuilder
+	user := b.arg(q.UserID)
+	role := categoryRole(user)
+	hasPermission := "(" + role + ") IN " + rolesFrom(models.RoleContributor)
+	if q.CreatedBy != 0 {
+		b.and("c.user_id = " + b.arg(q.CreatedBy))
+	}
+	if q.HasPermission != nil {
+		b.and(hasPermission + " = " + b.arg(*q.HasPermission))
+	}
+	tail, err := b.page(categorySorts, "c.id", q)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			c.id,
+			c.name,
+			COALESCE(c.user_id, 0),
+			COALESCE(u.first_name || ' ' || u.last_name, 'Unknown'),
+			c.created_at,
+			` + role + ` as role,
+			` + hasPermission + ` as has_permission,
+			COALESCE((SELECT status FROM category_permissions WHERE category_id=c.id AND user_id=` + user + `), '') as request_status
+		FROM categories c
+		LEFT JOIN users u ON c.user_id = u.id` + tail
+	rows, err := p.DB.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var cat models.Category
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.UserID, &cat.CreatorName, &cat.CreatedAt, &cat.Role, &cat.HasPermission, &cat.RequestStatus); err != nil {
+			return nil, err
+		}
+		categories = append(categories, cat)
+	}
+	return categories, rows.Err()
+}
+
+func (p *Postgres) GetCategory(ctx context.Context, id int) (*models.Category, error) {
+	var cat models.Category
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT c.id, c.name, COALESCE(c.user_id, 0), COALESCE(u.first_name || ' ' || u.last_name, 'Unknown'), c.created_at
+		FROM categories c
+		LEFT JOIN users u ON c.user_id = u.id
+		WHERE c.id = $1
+	`, id).Scan(&cat.ID, &cat.Name, &cat.UserID, &cat.CreatorName, &cat.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+func (p *Postgres) CreateCategory(ctx context.Context, cat *models.Category) error {
+	err := p.DB.QueryRowContext(ctx,
+		"INSERT INTO categories (name, user_id) VALUES ($1, $2) RETURNING id, created_at",
+		cat.Name, cat.UserID,
+	).Scan(&cat.ID, &cat.CreatedAt)
+	return constraintError(err)
+}
+
+func (p *Postgres) DeleteCategory(ctx context.Context, id int) error {
+	return expectRow(p.DB.ExecContext(ctx, "DELETE FROM categories WHERE id=$1", id))
+}
+
+func (p *Postgres) TransferCategory(ctx context.Context, id, toUserID int) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(user_id, 0) FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&from)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	var userExists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", toUserID).Scan(&userExists); err != nil {
+		return err
+	}
+	if !userExists {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE categories SET user_id = $2 WHERE id = $1", id, toUserID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM category_permissions WHERE category_id = $1 AND user_id = $2", id, toUserID); err != nil {
+		return err
+	}
+	if from != 0 && from != toUserID {
+		if _, err := tx.ExecContext(ctx, upsertMember, id, from, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Questions
+
+// questionColumns selects a models.Question from "questions q" in the order
+// questionDest scans it.
+const questionColumns = `q.id, q.category_id, q.question, q.answer, COALESCE(q.context, ''), q.difficulty,
+	ARRAY(SELECT t.name FROM question_tags qt JOIN tags t ON t.id = qt.tag_id WHERE qt.question_id = q.id ORDER BY t.name),
+	COALESCE(q.created_by, 0), q.created_at, q.updated_at`
+
+func questionDest(q *models.Question) []any {
+	return []any{&q.ID, &q.CategoryID, &q.Question, &q.Answer, &q.Context, &q.Difficulty, pq.Array(&q.Tags), &q.CreatedBy, &q.CreatedAt, &q.UpdatedAt}
+}
+
+func (p *Postgres) ListQuestions(ctx context.Context, lq models.ListQuery) ([]models.Question, error) {
+	var b listBuilder
+	b.and("q.deleted_at IS NULL")
+	if lq.CategoryID != 0 {
+		b.and("q.category_id = " + b.arg(lq.CategoryID))
+	}
+	if lq.Difficulty != "" {
+		b.and("q.difficulty = " + b.arg(lq.Difficulty))
+	}
+	if lq.CreatedBy != 0 {
+		b.and("q.created_by = " + b.arg(lq.CreatedBy))
+	}
+	for _, tag := range lq.Tags {
+		b.and("EXISTS(SELECT 1 FROM question_tags qt JOIN tags t ON t.id = qt.tag_id WHERE qt.question_id = q.id AND t.name = " + b.arg(tag) + ")")
+	}
+	if lq.UserID != 0 && !lq.Admin {
+		user := b.arg(lq.UserID)
+		b.and("q.category_id IN (SELECT id FROM categories WHERE user_id = " + user +
+			" UNION SELECT category_id FROM category_permissions WHERE user_id = " + user + " AND status = 'APPROVED')")
+	}
+	tail, err := b.page(questionSorts, "q.id", lq)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, "SELECT "+questionColumns+" FROM questions q"+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []models.Question
+	for rows.Next() {
+		var q models.Question
+		if err := rows.Scan(questionDest(&q)...); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+func (p *Postgres) GetQuestion(ctx context.Context, id int) (*models.Question, error) {
+	var q models.Question
+	err := p.DB.QueryRowContext(ctx, "SELECT "+questionColumns+" FROM questions q WHERE q.id = $1 AND q.deleted_at IS NULL", id).Scan(questionDest(&q)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+const insertQuestion = "INSERT INTO questions (category_id, question, answer, context, difficulty, created_by) VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0)) RETURNING id, created_at, updated_at"
+
+// insertRevision records the next revision of question $1.
+const insertRevision = `INSERT INTO question_revisions (question_id, revision, question, answer, context, difficulty, edited_by, restored_from)
+	SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0)
+	FROM question_revisions WHERE question_id = $1`
+
+func (p *Postgres) CreateQuestion(ctx context.Context, q *models.Question) error {
+	// Same transaction as an import of one, so the revision is never missing
+	questions := []models.Question{*q}
+	if err := p.ImportQuestions(ctx, questions); err != nil {
+		return err
+	}
+	*q = questions[0]
+	return nil
+}
+
+func (p *Postgres) ImportQuestions(ctx context.Context, questions []models.Question) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertQuestion)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	revStmt, err := tx.PrepareContext(ctx, insertRevision)
+	if err != nil {
+		return err
+	}
+	defer revStmt.Close()
+
+	for i := range questions {
+		q := &questions[i]
+		err := stmt.QueryRowContext(ctx,
+			q.CategoryID, q.Question, q.Answer, q.Context, q.Difficulty, q.CreatedBy,
+		).Scan(&q.ID, &q.CreatedAt, &q.UpdatedAt)
+		if err != nil {
+			return constraintError(err)
+		}
+		_, err = revStmt.ExecContext(ctx, q.ID, q.Question, q.Answer, q.Context, q.Difficulty, q.CreatedBy, 0)
+		if err != nil {
+			return err
+		}
+		if len(q.Tags) == 0 {
+			q.Tags = []string{}
+		} else if err := setQuestionTags(ctx, tx, q, q.CreatedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// setQuestionTags replaces q's tags with q.Tags, creating the ones its
+// category doesn't have yet on behalf of userID.
+func setQuestionTags(ctx context.Context, tx *sql.Tx, q *models.Question, userID int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tags (category_id, name, created_by)
+		SELECT $1, unnest($2::text[]), NULLIF($3, 0)
+		ON CONFLICT (category_id, name) DO NOTHING
+	`, q.CategoryID, pq.Array(q.Tags), userID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM question_tags WHERE question_id = $1", q.ID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO question_tags (question_id, tag_id)
+		SELECT $1, id FROM tags WHERE category_id = $2 AND name = ANY($3)
+	`, q.ID, q.CategoryID, pq.Array(q.Tags))
+	return err
+}
+
+func (p *Postgres) UpdateQuestion(ctx context.Context, q *models.Question, editorID int) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The row lock taken here keeps concurrent edits from picking the same
+	// revision number
+	err = tx.QueryRowContext(ctx,
+		"UPDATE questions SET question=$1, answer=$2, context=$3, difficulty=$4, updated_at=CURRENT_TIMESTAMP WHERE id=$5 AND deleted_at IS NULL RETURNING category_id, updated_at",
+		q.Question, q.Answer, q.Context, q.Difficulty, q.ID,
+	).Scan(&q.CategoryID, &q.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, insertRevision, q.ID, q.Question, q.Answer, q.Context, q.Difficulty, editorID, 0); err != nil {
+		return err
+	}
+	if q.Tags != nil {
+		if err := setQuestionTags(ctx, tx, q, editorID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) DeleteQuestion(ctx context.Context, id, userID int) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE questions SET deleted_at=CURRENT_TIMESTAMP, deleted_by=NULLIF($2, 0) WHERE id=$1 AND deleted_at IS NULL",
+		id, userID,
+	))
+}
+
+// Permissions
+
+// categoryRole is the SQL for the role of the user in placeholder user on
+// the category aliased c.
+func categoryRole(user string) string {
+	return fmt.Sprintf(`CASE WHEN c.user_id = %[1]s THEN 'owner'
+		ELSE COALESCE((SELECT role FROM category_permissions WHERE category_id = c.id AND user_id = %[1]s AND status = 'APPROVED'), '') END`, user)
+}
+
+// rolesFrom is a SQL list of min and every stronger role.
+func rolesFrom(min string) string {
+	roles := models.CategoryRoles[slices.Index(models.CategoryRoles, min):]
+	return "('" + strings.Join(roles, "', '") + "')"
+}
+
+func (p *Postgres) CategoryRole(ctx context.Context, categoryID, userID int) (string, error) {
+	var role string
+	err := p.DB.QueryRowContext(ctx, "SELECT "+categoryRole("$2")+" FROM categories c WHERE c.id = $1", categoryID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return role, err
+}
+
+func (p *Postgres) CreateRequest(ctx context.Context, categoryID, userID int) error {
+	var exists bool
+	err := p.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM category_permissions WHERE category_id=$1 AND user_id=$2)", categoryID, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrConflict
+	}
+
+	_, err = p.DB.ExecContext(ctx, "INSERT INTO category_permissions (category_id, user_id) VALUES ($1, $2)", categoryID, userID)
+	return constraintError(err)
+}
+
+// requestColumns selects a models.AccessRequest from "category_permissions
+// p JOIN users u" in the order requestDest scans it.
+const requestColumns = "p.id, p.category_id, p.user_id, u.first_name, u.last_name, u.email, p.status, p.role, p.created_at"
+
+func requestDest(r *models.AccessRequest) []any {
+	return []any{&r.ID, &r.CategoryID, &r.UserID, &r.User.FirstName, &r.User.LastName, &r.User.Email, &r.Status, &r.Role, &r.CreatedAt}
+}
+
+func (p *Postgres) GetRequest(ctx context.Context, id int) (*models.AccessRequest, error) {
+	var r models.AccessRequest
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT `+requestColumns+`
+		FROM category_permissions p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.id = $1
+	`, id).Scan(requestDest(&r)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (p *Postgres) ListPendingRequests(ctx context.Context, categoryID int) ([]models.AccessRequest, error) {
+	return p.listRequests(ctx, categoryID, "PENDING")
+}
+
+func (p *Postgres) ListMembers(ctx context.Context, categoryID int) ([]models.AccessRequest, error) {
+	return p.listRequests(ctx, categoryID, "APPROVED")
+}
+
+func (p *Postgres) listRequests(ctx context.Context, categoryID int, status string) ([]models.AccessRequest, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+requestColumns+`
+		FROM category_permissions p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.category_id = $1 AND p.status = $2
+		ORDER BY p.id
+	`, categoryID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.AccessRequest
+	for rows.Next() {
+		var r models.AccessRequest
+		if err := rows.Scan(requestDest(&r)...); err != nil {
+			return nil, err
+		}
+		requests = append(requests, r)
+	}
+	return requests, rows.Err()
+}
+
+func (p *Postgres) UpdateRequestStatus(ctx context.Context, id int, status, role string) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE category_permissions SET status=$1, role=COALESCE(NULLIF($2, ''), role) WHERE id=$3",
+		status, role, id,
+	))
+}
+
+// upsertMember approves $2 as a member of category $1 with role $3,
+// selecting from users so a missing user inserts nothing.
+const upsertMember = `
+	INSERT INTO category_permissions (category_id, user_id, status, role)
+	SELECT $1, id, 'APPROVED', $3 FROM users WHERE id = $2
+	ON CONFLICT (category_id, user_id) DO UPDATE SET status = 'APPROVED', role = EXCLUDED.role`
+
+func (p *Postgres) SetMemberRole(ctx context.Context, categoryID, userID int, role string) error {
+	return expectRow(p.DB.ExecContext(ctx, upsertMember, categoryID, userID, role))
+}
+
+func (p *Postgres) RemoveMember(ctx context.Context, categoryID, userID int) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"DELETE FROM category_permissions WHERE category_id = $1 AND user_id = $2 AND status = 'APPROVED'",
+		categoryID, userID,
+	))
+}
+
+// Users
+
+func (p *Postgres) CreateUser(ctx context.Context, u *models.User) error {
+	err := p.DB.QueryRowContext(ctx,
+		"INSERT INTO users (first_name, last_name, email, password, phone, role, email_verified_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at",
+		u.FirstName, u.LastName, u.Email, u.Password, u.Phone, u.Role, u.EmailVerifiedAt,
+	).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	return constraintError(err)
+}
+
+func (p *Postgres) EmailOrPhoneTaken(ctx context.Context, email, phone string) (bool, error) {
+	var count int
+	err := p.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE email = $1 OR phone = $2", email, phone).Scan(&count)
+	return count > 0, err
+}
+
+// userColumns selects a models.User, less its password, from "users u" in
+// the order userDest scans it.
+const userColumns = `u.id, u.first_name, u.last_name, u.email, COALESCE(u.phone, ''), u.role, u.email_verified_at,
+	u.locked_at, u.password_reset_required, u.suspended_at, u.suspended_until, u.suspension_reason, u.created_at, u.updated_at`
+
+func userDest(u *models.User) []any {
+	return []any{&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Role, &u.EmailVerifiedAt,
+		&u.LockedAt, &u.PasswordResetRequired, &u.SuspendedAt, &u.SuspendedUntil, &u.SuspensionReason, &u.CreatedAt, &u.UpdatedAt}
+}
+
+func (p *Postgres) GetUser(ctx context.Context, id int) (*models.User, error) {
+	var u models.User
+	err := p.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users u WHERE u.id = $1", id).Scan(userDest(&u)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (p *Postgres) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var u models.User
+	err := p.DB.QueryRowContext(ctx, "SELECT "+userColumns+", u.password FROM users u WHERE u.email = $1", email).Scan(
+		append(userDest(&u), &u.Password)...,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// userStates are the conditions on "users u" for the Status filter.
+var userStates = map[string]string{
+	models.UserActive:     "u.locked_at IS NULL AND u.email_verified_at IS NOT NULL AND NOT " + userSuspended,
+	models.UserLocked:     "u.locked_at IS NOT NULL",
+	models.UserSuspended:  userSuspended,
+	models.UserUnverified: "u.email_verified_at IS NULL",
+}
+
+func (p *Postgres) ListUsers(ctx context.Context, q models.ListQuery) ([]models.User, error) {
+	var b listBuilder
+	if q.Search != "" {
+		pattern := b.arg("%" + likeEscaper.Replace(q.Search) + "%")
+		b.and("((u.first_name || ' ' || u.last_name) ILIKE " + pattern + " OR u.email ILIKE " + pattern + ")")
+	}
+	if q.Role != "" {
+		b.and("u.role = " + b.arg(q.Role))
+	}
+	if q.Status != "" {
+		b.and(userStates[q.Status])
+	}
+	tail, err := b.page(userSorts, "u.id", q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, "SELECT "+userColumns+" FROM users u"+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(userDest(&u)...); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// updateUser saves u's profile inside tx, returning the user as it was.
+func updateUser(ctx context.Context, tx *sql.Tx, u *models.User) (*models.User, error) {
+	var old models.User
+	err := tx.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users u WHERE u.id = $1 FOR UPDATE", u.ID).Scan(userDest(&old)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Signup keeps phones unique without a constraint, so check both here
+	var taken bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM users WHERE id <> $1 AND (email = $2 OR (phone = $3 AND phone <> '')))",
+		u.ID, u.Email, u.Phone,
+	).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrConflict
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users u
+		SET first_name = $2, last_name = $3, email = $4, phone = NULLIF($5, ''), email_verified_at = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE u.id = $1
+		RETURNING `+userColumns,
+		u.ID, u.FirstName, u.LastName, u.Email, u.Phone, u.EmailVerifiedAt,
+	).Scan(userDest(u)...)
+	if err != nil {
+		return nil, constraintError(err)
+	}
+	// An account waiting on a new verification can't be signed in
+	if old.EmailVerifiedAt != nil && u.EmailVerifiedAt == nil {
+		if err := revokeSessions(ctx, tx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &old, nil
+}
+
+func (p *Postgres) UpdateUser(ctx context.Context, u *models.User) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := updateUser(ctx, tx, u); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) ChangePassword(ctx context.Context, userID int, passwordHash, keepSessionID string) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := expectRow(tx.ExecContext(ctx,
+		"UPDATE users SET password = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1", userID, passwordHash,
+	)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL",
+		userID, keepSessionID,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) UserSummary(ctx context.Context, userID int) (*models.UserSummary, error) {
+	u, err := p.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := models.UserSummary{User: *u, OwnedCategories: []models.OwnedCategory{}, Permissions: []models.UserPermission{}}
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT c.id, c.name,
+			(SELECT COUNT(*) FROM questions q WHERE q.category_id = c.id AND q.deleted_at IS NULL),
+			(SELECT COUNT(*) FROM category_permissions p WHERE p.category_id = c.id AND p.status = 'APPROVED'),
+			c.created_at
+		FROM categories c
+		WHERE c.user_id = $1
+		ORDER BY c.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c models.OwnedCategory
+		if err := rows.Scan(&c.ID, &c.Name, &c.QuestionCount, &c.MemberCount, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		summary.OwnedCategories = append(summary.OwnedCategories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = p.DB.QueryContext(ctx, `
+		SELECT c.id, c.name, p.status, p.role, p.created_at
+		FROM category_permissions p
+		JOIN categories c ON c.id = p.category_id
+		WHERE p.user_id = $1
+		ORDER BY c.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var perm models.UserPermission
+		if err := rows.Scan(&perm.CategoryID, &perm.CategoryName, &perm.Status, &perm.Role, &perm.CreatedAt); err != nil {
+			return nil, err
+		}
+		summary.Permissions = append(summary.Permissions, perm)
+	}
+	return &summary, rows.Err()
+}
+
+// expectRow turns an UPDATE or DELETE that touched nothing into ErrNotFound.
+func expectRow(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SQLSTATE codes of the constraint violations the store translates.
+const (
+	uniqueViolation     = pq.ErrorCode("23505")
+	foreignKeyViolation = pq.ErrorCode("23503")
+)
+
+// constraintError turns a constraint violation into the store's errors: a
+// duplicate into ErrConflict and a reference to a missing row into
+// ErrNotFound. Every foreign key cascades or nulls on delete, so only
+// inserts and updates can violate one. Other errors pass through.
+func constraintError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case uniqueViolation:
+		return fmt.Errorf("%w: %s", ErrConflict, pqErr.Constraint)
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %s", ErrNotFound, pqErr.Constraint)
+	}
+	return err
+}
+
+// intArray passes ids as a Postgres integer array. pq turns a nil slice into
+// NULL, which would make "= ANY($n)" match nothing, so nil becomes '{}'.
+func intArray(ids []int) interface{} {
+	if ids == nil {
+		ids = []int{}
+	}
+	return pq.Array(ids)
+}
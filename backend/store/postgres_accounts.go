@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+func (p *Postgres) CreateAccountToken(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM account_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL",
+		userID, purpose,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		userID, purpose, tokenHash, expiresAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) CheckAccountToken(ctx context.Context, purpose, tokenHash string) error {
+	var live bool
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM account_tokens
+			WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		)
+	`, tokenHash, purpose).Scan(&live)
+	if err != nil {
+		return err
+	}
+	if !live {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// consumeAccountToken marks a live token used and returns its user.
+func consumeAccountToken(ctx context.Context, tx *sql.Tx, purpose, tokenHash string) (int, error) {
+	var userID int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE account_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`, tokenHash, purpose).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	return userID, err
+}
+
+func (p *Postgres) VerifyEmail(ctx context.Context, tokenHash string) (*models.User, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeAccountToken(ctx, tx, models.TokenVerifyEmail, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $1", userID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.GetUser(ctx, userID)
+}
+
+func (p *Postgres) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*models.User, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeAccountToken(ctx, tx, models.TokenResetPassword, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users SET password = $2, email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP),
+			password_reset_required = FALSE, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID, passwordHash); err != nil {
+		return nil, err
+	}
+	if err := revokeSessions(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.GetUser(ctx, userID)
+}
@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"interview-prep/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// audit appends an entry to the audit log inside tx.
+func audit(ctx context.Context, tx *sql.Tx, actorID int, action string, targetID int, details map[string]any) error {
+	if details == nil {
+		details = map[string]any{}
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO audit_log (actor_id, action, target_user_id, details) VALUES (NULLIF($1, 0), $2, NULLIF($3, 0), $4)",
+		actorID, action, targetID, raw,
+	)
+	return err
+}
+
+func revokeSessions(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", userID,
+	)
+	return err
+}
+
+func (p *Postgres) CreateAdmin(ctx context.Context, actorID int, u *models.User) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	u.Role = models.RoleAdmin
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (first_name, last_name, email, password, phone, role, email_verified_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id, email_verified_at, created_at, updated_at
+	`, u.FirstName, u.LastName, u.Email, u.Password, u.Phone, u.Role).Scan(&u.ID, &u.EmailVerifiedAt, &u.CreatedAt, &u.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrConflict
+	} else if err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditAdminCreated, u.ID, map[string]any{"email": u.Email}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) SetUserRole(ctx context.Context, actorID, userID int, role string) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the admins so two demotions can't both see another admin left
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE role = 'ADMIN' FOR UPDATE"); err != nil {
+		return err
+	}
+	var from string
+	err = tx.QueryRowContext(ctx, "SELECT role FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if from == role {
+		return nil
+	}
+	if from == models.RoleAdmin {
+		var others int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE role = 'ADMIN' AND id <> $1", userID).Scan(&others); err != nil {
+			return err
+		}
+		if others == 0 {
+			return ErrLastAdmin
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET role = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1", userID, role); err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, tx, userID); err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditRoleChanged, userID, map[string]any{"from": from, "to": role}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) SetUserLocked(ctx context.Context, actorID, userID int, locked bool) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	action := models.AuditUserUnlocked
+	query := "UPDATE users SET locked_at = NULL WHERE id = $1"
+	if locked {
+		action = models.AuditUserLocked
+		query = "UPDATE users SET locked_at = COALESCE(locked_at, CURRENT_TIMESTAMP) WHERE id = $1"
+	}
+	if err := expectRow(tx.ExecContext(ctx, query, userID)); err != nil {
+		return err
+	}
+	if locked {
+		if err := revokeSessions(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+	if err := audit(ctx, tx, actorID, action, userID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) RequirePasswordReset(ctx context.Context, actorID, userID int) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := expectRow(tx.ExecContext(ctx, "UPDATE users SET password_reset_required = TRUE WHERE id = $1", userID)); err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, tx, userID); err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditPasswordResetForced, userID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) ListAuditLog(ctx context.Context, q models.ListQuery) ([]models.AuditEntry, error) {
+	var b listBuilder
+	if q.TargetUserID != 0 {
+		b.and("a.target_user_id = " + b.arg(q.TargetUserID))
+	}
+	tail, err := b.page(auditSorts, "a.id", q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, "SELECT a.id, a.actor_id, a.action, a.target_user_id, a.details, a.created_at FROM audit_log a"+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetUserID, &details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &e.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// profileChanges lists the profile fields that differ between old and u,
+// for the audit log.
+func profileChanges(old, u *models.User) map[string]any {
+	changes := map[string]any{}
+	for field, values := range map[string][2]string{
+		"first_name": {old.FirstName, u.FirstName},
+		"last_name":  {old.LastName, u.LastName},
+		"email":      {old.Email, u.Email},
+		"phone":      {old.Phone, u.Phone},
+	} {
+		if values[0] != values[1] {
+			changes[field] = map[string]string{"from": values[0], "to": values[1]}
+		}
+	}
+	return changes
+}
+
+func (p *Postgres) EditUser(ctx context.Context, actorID int, u *models.User) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	old, err := updateUser(ctx, tx, u)
+	if err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditUserUpdated, u.ID, profileChanges(old, u)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) SuspendUser(ctx context.Context, actorID, userID int, until *time.Time, reason string) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := expectRow(tx.ExecContext(ctx,
+		"UPDATE users SET suspended_at = CURRENT_TIMESTAMP, suspended_until = $2, suspension_reason = $3 WHERE id = $1",
+		userID, until, reason,
+	)); err != nil {
+		return err
+	}
+	if err := revokeSessions(ctx, tx, userID); err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditUserSuspended, userID, map[string]any{"until": until, "reason": reason}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) UnsuspendUser(ctx context.Context, actorID, userID int) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := expectRow(tx.ExecContext(ctx,
+		"UPDATE users SET suspended_at = NULL, suspended_until = NULL, suspension_reason = '' WHERE id = $1", userID,
+	)); err != nil {
+		return err
+	}
+	if err := audit(ctx, tx, actorID, models.AuditUserUnsuspended, userID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) DeleteUser(ctx context.Context, actorID, userID, reassignTo int) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Same locking as SetUserRole, so a deletion and a demotion can't
+	// together remove the last admin
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE role = 'ADMIN' FOR UPDATE"); err != nil {
+		return err
+	}
+	var role, email string
+	err = tx.QueryRowContext(ctx, "SELECT role, email FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&role, &email)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if role == models.RoleAdmin {
+		var others int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE role = 'ADMIN' AND id <> $1", userID).Scan(&others); err != nil {
+			return err
+		}
+		if others == 0 {
+			return ErrLastAdmin
+		}
+	}
+
+	details := map[string]any{"user_id": userID, "email": email}
+	var categoryIDs []int64
+	if reassignTo != 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", reassignTo).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+		err = tx.QueryRowContext(ctx,
+			"WITH moved AS (UPDATE categories SET user_id = $2 WHERE user_id = $1 RETURNING id) SELECT COALESCE(array_agg(id ORDER BY id), '{}') FROM moved",
+			userID, reassignTo,
+		).Scan(pq.Array(&categoryIDs))
+		if err != nil {
+			return err
+		}
+		// The new owner no longer needs a membership on them
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM category_permissions WHERE user_id = $1 AND category_id = ANY($2)", reassignTo, pq.Array(categoryIDs),
+		); err != nil {
+			return err
+		}
+		details["reassigned_to"] = reassignTo
+		details["reassigned_categories"] = categoryIDs
+	} else {
+		err = tx.QueryRowContext(ctx,
+			"WITH deleted AS (DELETE FROM categories WHERE user_id = $1 RETURNING id) SELECT COALESCE(array_agg(id ORDER BY id), '{}') FROM deleted",
+			userID,
+		).Scan(pq.Array(&categoryIDs))
+		if err != nil {
+			return err
+		}
+		details["deleted_categories"] = categoryIDs
+	}
+
+	// The entry outlives the user; its target_user_id is cleared with them
+	if err := audit(ctx, tx, actorID, models.AuditUserDeleted, userID, details); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
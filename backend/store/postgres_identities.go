@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+)
+
+// unusablePassword is stored for users created through an identity
+// provider. It is no bcrypt hash, so no password matches it until the user
+// sets one with a reset link.
+const unusablePassword = "!"
+
+func (p *Postgres) SaveOIDCLogin(ctx context.Context, l *models.OIDCLogin) error {
+	// Abandoned sign-ins are cleared as new ones start
+	if _, err := p.DB.ExecContext(ctx, "DELETE FROM oidc_logins WHERE expires_at <= CURRENT_TIMESTAMP"); err != nil {
+		return err
+	}
+	_, err := p.DB.ExecContext(ctx,
+		"INSERT INTO oidc_logins (state_hash, nonce, code_verifier, expires_at) VALUES ($1, $2, $3, $4)",
+		l.StateHash, l.Nonce, l.CodeVerifier, l.ExpiresAt,
+	)
+	return err
+}
+
+func (p *Postgres) TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	l := models.OIDCLogin{StateHash: stateHash}
+	err := p.DB.QueryRowContext(ctx,
+		"DELETE FROM oidc_logins WHERE state_hash = $1 AND expires_at > CURRENT_TIMESTAMP RETURNING nonce, code_verifier, expires_at",
+		stateHash,
+	).Scan(&l.Nonce, &l.CodeVerifier, &l.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (p *Postgres) SignInWithIdentity(ctx context.Context, id models.ExternalIdentity) (*models.User, bool, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE user_identities SET email = $3, last_login_at = CURRENT_TIMESTAMP
+		WHERE issuer = $1 AND subject = $2
+		RETURNING user_id
+	`, id.Issuer, id.Subject, id.Email).Scan(&userID)
+	created := false
+	switch {
+	case err == sql.ErrNoRows:
+		if userID, created, err = linkIdentity(ctx, tx, id); err != nil {
+			return nil, false, err
+		}
+	case err != nil:
+		return nil, false, err
+	}
+
+	var u models.User
+	if err := tx.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users u WHERE u.id = $1", userID).Scan(userDest(&u)...); err != nil {
+		return nil, false, err
+	}
+	return &u, created, tx.Commit()
+}
+
+// linkIdentity links a new identity to the user with its email, or to a
+// new user if there is none.
+func linkIdentity(ctx context.Context, tx *sql.Tx, id models.ExternalIdentity) (userID int, created bool, err error) {
+	err = tx.QueryRowContext(ctx, "SELECT id FROM users WHERE email = $1 FOR UPDATE", id.Email).Scan(&userID)
+	switch {
+	case err == sql.ErrNoRows:
+		created = true
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (first_name, last_name, email, password, role, email_verified_at)
+			VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN CURRENT_TIMESTAMP END)
+			RETURNING id
+		`, id.FirstName, id.LastName, id.Email, unusablePassword, models.RoleUser, id.EmailVerified).Scan(&userID)
+		if err != nil {
+			return 0, false, constraintError(err)
+		}
+	case err != nil:
+		return 0, false, err
+	case !id.EmailVerified:
+		// Anyone can claim an address at some providers
+		return 0, false, ErrConflict
+	default:
+		// Whoever signed up with the address without verifying it may not
+		// own it, so their password and sessions go. The provider has shown
+		// this user does.
+		err := expectRow(tx.ExecContext(ctx, `
+			UPDATE users SET password = $2, email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND email_verified_at IS NULL
+		`, userID, unusablePassword))
+		switch {
+		case err == ErrNotFound:
+			// Verified already; nothing to take back
+		case err != nil:
+			return 0, false, err
+		default:
+			if err := revokeSessions(ctx, tx, userID); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO user_identities (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4)",
+		userID, id.Issuer, id.Subject, id.Email,
+	)
+	if err != nil {
+		return 0, false, constraintError(err)
+	}
+	return userID, created, nil
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+func (p *Postgres) SampleQuestions(ctx context.Context, categoryIDs []int, difficulty string, limit int, exclude []int) ([]models.Question, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM questions q
+		WHERE q.category_id = ANY($1)
+			AND ($2 = '' OR q.difficulty = $2)
+			AND NOT (q.id = ANY($3))
+			AND q.deleted_at IS NULL
+		ORDER BY random()
+		LIMIT $4
+	`, intArray(categoryIDs), difficulty, intArray(exclude), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []models.Question
+	for rows.Next() {
+		var q models.Question
+		if err := rows.Scan(questionDest(&q)...); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+func (p *Postgres) CreateInterview(ctx context.Context, s *models.InterviewSession) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO interview_sessions (user_id, status, time_limit_seconds) VALUES ($1, $2, $3) RETURNING id, created_at",
+		s.UserID, s.Status, s.TimeLimitSeconds,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range s.Questions {
+		q := &s.Questions[i]
+		q.SessionID = s.ID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO interview_questions (session_id, position, question_id, category_id, question, answer, difficulty)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		`, q.SessionID, q.Position, q.QuestionID, q.CategoryID, q.Question, q.Answer, q.Difficulty).Scan(&q.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) ListInterviews(ctx context.Context, userID int) ([]models.InterviewSession, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT id, user_id, status, time_limit_seconds, created_at, completed_at
+		FROM interview_sessions WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.InterviewSession{}
+	for rows.Next() {
+		var s models.InterviewSession
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Status, &s.TimeLimitSeconds, &s.CreatedAt, &s.CompletedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (p *Postgres) GetInterview(ctx context.Context, id int) (*models.InterviewSession, error) {
+	var s models.InterviewSession
+	err := p.DB.QueryRowContext(ctx,
+		"SELECT id, user_id, status, time_limit_seconds, created_at, completed_at FROM interview_sessions WHERE id = $1", id,
+	).Scan(&s.ID, &s.UserID, &s.Status, &s.TimeLimitSeconds, &s.CreatedAt, &s.CompletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT id, session_id, position, COALESCE(question_id, 0), COALESCE(category_id, 0), question, COALESCE(answer, ''),
+			COALESCE(difficulty, ''), served_at, answered_at, COALESCE(response, ''), time_taken_ms, self_rating, timed_out
+		FROM interview_questions WHERE session_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q models.InterviewQuestion
+		var timeTaken, rating sql.NullInt64
+		if err := rows.Scan(&q.ID, &q.SessionID, &q.Position, &q.QuestionID, &q.CategoryID, &q.Question, &q.Answer,
+			&q.Difficulty, &q.ServedAt, &q.AnsweredAt, &q.Response, &timeTaken, &rating, &q.TimedOut); err != nil {
+			return nil, err
+		}
+		if timeTaken.Valid {
+			v := int(timeTaken.Int64)
+			q.TimeTakenMs = &v
+		}
+		if rating.Valid {
+			v := int(rating.Int64)
+			q.SelfRating = &v
+		}
+		s.Questions = append(s.Questions, q)
+	}
+	return &s, rows.Err()
+}
+
+func (p *Postgres) MarkInterviewQuestionServed(ctx context.Context, id int, at time.Time) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE interview_questions SET served_at = $1 WHERE id = $2 AND served_at IS NULL", at, id,
+	))
+}
+
+func (p *Postgres) SaveInterviewResponse(ctx context.Context, q *models.InterviewQuestion) error {
+	return expectRow(p.DB.ExecContext(ctx, `
+		UPDATE interview_questions
+		SET answered_at = $1, response = $2, time_taken_ms = $3, self_rating = $4, timed_out = $5
+		WHERE id = $6 AND answered_at IS NULL
+	`, q.AnsweredAt, q.Response, q.TimeTakenMs, q.SelfRating, q.TimedOut, q.ID))
+}
+
+func (p *Postgres) CompleteInterview(ctx context.Context, id int, at time.Time) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE interview_sessions SET status = $1, completed_at = $2 WHERE id = $3 AND status = $4",
+		models.InterviewCompleted, at, id, models.InterviewInProgress,
+	))
+}
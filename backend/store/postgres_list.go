@@ -0,0 +1,83 @@
+package store
+
+import (
+	"fmt"
+	"interview-prep/models"
+	"strings"
+)
+
+// sortExpr orders a list by column. format wraps either the column or the
+// cursor placeholder, so both sides of the keyset comparison are computed
+// the same way.
+type sortExpr struct {
+	column string
+	format string
+}
+
+const difficultyRank = "CASE %s::text WHEN 'Easy' THEN 1 WHEN 'Medium' THEN 2 WHEN 'Hard' THEN 3 ELSE 4 END"
+
+var questionSorts = map[string]sortExpr{
+	models.SortCreatedAt:  {"q.created_at", "%s::timestamp"},
+	models.SortUpdatedAt:  {"q.updated_at", "%s::timestamp"},
+	models.SortDifficulty: {"q.difficulty", difficultyRank},
+}
+
+var categorySorts = map[string]sortExpr{
+	models.SortName:      {"c.name", "lower(%s::text)"},
+	models.SortCreatedAt: {"c.created_at", "%s::timestamp"},
+}
+
+var userSorts = map[string]sortExpr{
+	models.SortName:      {"(u.first_name || ' ' || u.last_name)", "lower(%s::text)"},
+	models.SortCreatedAt: {"u.created_at", "%s::timestamp"},
+}
+
+var auditSorts = map[string]sortExpr{
+	models.SortCreatedAt: {"a.created_at", "%s::timestamp"},
+}
+
+// listBuilder collects the WHERE conditions of a paged list query along
+// with their arguments.
+type listBuilder struct {
+	where []string
+	args  []any
+}
+
+// arg adds a query argument and returns its placeholder.
+func (b *listBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *listBuilder) and(cond string) {
+	b.where = append(b.where, cond)
+}
+
+// page finishes the query with the cursor condition, the ORDER BY and a
+// LIMIT one past the page size.
+func (b *listBuilder) page(sorts map[string]sortExpr, idColumn string, q models.ListQuery) (string, error) {
+	s, ok := sorts[q.Sort]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort %q", q.Sort)
+	}
+	expr := fmt.Sprintf(s.format, s.column)
+
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if q.After != nil {
+		value := fmt.Sprintf(s.format, b.arg(q.After.Value))
+		b.and(fmt.Sprintf("(%s, %s) %s (%s, %s)", expr, idColumn, cmp, value, b.arg(q.After.ID)))
+	}
+
+	var sb strings.Builder
+	if len(b.where) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(b.where, " AND "))
+	}
+	fmt.Fprintf(&sb, " ORDER BY %s %s, %s %s", expr, dir, idColumn, dir)
+	if q.Limit > 0 {
+		sb.WriteString(" LIMIT " + b.arg(q.Limit+1))
+	}
+	return sb.String(), nil
+}
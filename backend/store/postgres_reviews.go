@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+func (p *Postgres) ListDueReviews(ctx context.Context, userID, categoryID int, now time.Time, limit int) ([]models.DueReview, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+questionColumns+`,
+			r.ease_factor, r.interval_days, r.repetitions, r.lapses, r.last_grade, r.due_at, r.last_reviewed_at
+		FROM questions q
+		JOIN categories c ON c.id = q.category_id
+		LEFT JOIN review_states r ON r.question_id = q.id AND r.user_id = $1
+		WHERE (c.user_id = $1 OR EXISTS(SELECT 1 FROM category_permissions WHERE category_id = c.id AND user_id = $1 AND status = 'APPROVED'))
+			AND ($2 = 0 OR q.category_id = $2)
+			AND (r.due_at IS NULL OR r.due_at <= $3)
+			AND q.deleted_at IS NULL
+		ORDER BY r.due_at IS NULL, r.due_at, q.created_at
+		LIMIT $4
+	`, userID, categoryID, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.DueReview{}
+	for rows.Next() {
+		var d models.DueReview
+		var easeFactor sql.NullFloat64
+		var intervalDays, repetitions, lapses, lastGrade sql.NullInt64
+		var dueAt, lastReviewedAt sql.NullTime
+		q := &d.Question
+		dest := append(questionDest(q), &easeFactor, &intervalDays, &repetitions, &lapses, &lastGrade, &dueAt, &lastReviewedAt)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if dueAt.Valid {
+			d.Review = &models.ReviewState{
+				UserID:         userID,
+				QuestionID:     q.ID,
+				EaseFactor:     easeFactor.Float64,
+				IntervalDays:   int(intervalDays.Int64),
+				Repetitions:    int(repetitions.Int64),
+				Lapses:         int(lapses.Int64),
+				LastGrade:      int(lastGrade.Int64),
+				DueAt:          dueAt.Time,
+				LastReviewedAt: lastReviewedAt.Time,
+			}
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+func (p *Postgres) GetReviewState(ctx context.Context, userID, questionID int) (*models.ReviewState, error) {
+	s := models.ReviewState{UserID: userID, QuestionID: questionID}
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT ease_factor, interval_days, repetitions, lapses, last_grade, due_at, last_reviewed_at
+		FROM review_states WHERE user_id = $1 AND question_id = $2
+	`, userID, questionID).Scan(&s.EaseFactor, &s.IntervalDays, &s.Repetitions, &s.Lapses, &s.LastGrade, &s.DueAt, &s.LastReviewedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (p *Postgres) SaveReviewState(ctx context.Context, s *models.ReviewState) error {
+	_, err := p.DB.ExecContext(ctx, `
+		INSERT INTO review_states (user_id, question_id, ease_factor, interval_days, repetitions, lapses, last_grade, due_at, last_reviewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, question_id) DO UPDATE SET
+			ease_factor = EXCLUDED.ease_factor,
+			interval_days = EXCLUDED.interval_days,
+			repetitions = EXCLUDED.repetitions,
+			lapses = EXCLUDED.lapses,
+			last_grade = EXCLUDED.last_grade,
+			due_at = EXCLUDED.due_at,
+			last_reviewed_at = EXCLUDED.last_reviewed_at
+	`, s.UserID, s.QuestionID, s.EaseFactor, s.IntervalDays, s.Repetitions, s.Lapses, s.LastGrade, s.DueAt, s.LastReviewedAt)
+	return err
+}
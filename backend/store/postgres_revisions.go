@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+func (p *Postgres) ListRevisions(ctx context.Context, questionID int) ([]models.QuestionRevision, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT r.question_id, r.revision, r.question, COALESCE(r.answer, ''), COALESCE(r.context, ''), COALESCE(r.difficulty, ''),
+			COALESCE(r.edited_by, 0), COALESCE(u.first_name || ' ' || u.last_name, 'Unknown'), COALESCE(r.restored_from, 0), r.created_at
+		FROM question_revisions r
+		LEFT JOIN users u ON u.id = r.edited_by
+		WHERE r.question_id = $1
+		ORDER BY r.revision DESC
+	`, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.QuestionRevision{}
+	for rows.Next() {
+		var r models.QuestionRevision
+		err := rows.Scan(&r.QuestionID, &r.Revision, &r.Question, &r.Answer, &r.Context, &r.Difficulty,
+			&r.EditedBy, &r.EditorName, &r.RestoredFrom, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+func (p *Postgres) RestoreRevision(ctx context.Context, questionID, rev, userID int) (*models.Question, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var q models.Question
+	err = tx.QueryRowContext(ctx, `
+		UPDATE questions q
+		SET question = r.question, answer = r.answer, context = r.context, difficulty = r.difficulty, updated_at = CURRENT_TIMESTAMP
+		FROM question_revisions r
+		WHERE q.id = $1 AND q.deleted_at IS NULL AND r.question_id = q.id AND r.revision = $2
+		RETURNING `+questionColumns,
+		questionID, rev,
+	).Scan(questionDest(&q)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, insertRevision, q.ID, q.Question, q.Answer, q.Context, q.Difficulty, userID, rev); err != nil {
+		return nil, err
+	}
+	return &q, tx.Commit()
+}
+
+// Trash
+
+const trashedColumns = questionColumns + ", q.deleted_at, COALESCE(q.deleted_by, 0)"
+
+func trashedDest(t *models.TrashedQuestion) []any {
+	return append(questionDest(&t.Question), &t.DeletedAt, &t.DeletedBy)
+}
+
+func (p *Postgres) ListTrash(ctx context.Context, userID, categoryID int, since time.Time) ([]models.TrashedQuestion, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+trashedColumns+`
+		FROM questions q
+		JOIN categories c ON c.id = q.category_id
+		WHERE q.deleted_at >= $1
+			AND ($2 = 0 OR c.user_id = $2 OR EXISTS(SELECT 1 FROM category_permissions WHERE category_id = c.id AND user_id = $2 AND status = 'APPROVED'))
+			AND ($3 = 0 OR q.category_id = $3)
+		ORDER BY q.deleted_at DESC, q.id DESC
+	`, since, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trash := []models.TrashedQuestion{}
+	for rows.Next() {
+		var t models.TrashedQuestion
+		if err := rows.Scan(trashedDest(&t)...); err != nil {
+			return nil, err
+		}
+		trash = append(trash, t)
+	}
+	return trash, rows.Err()
+}
+
+func (p *Postgres) GetTrashedQuestion(ctx context.Context, id int, since time.Time) (*models.TrashedQuestion, error) {
+	var t models.TrashedQuestion
+	err := p.DB.QueryRowContext(ctx,
+		"SELECT "+trashedColumns+" FROM questions q WHERE q.id = $1 AND q.deleted_at >= $2", id, since,
+	).Scan(trashedDest(&t)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) RestoreQuestion(ctx context.Context, id int, since time.Time) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE questions SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at >= $2", id, since,
+	))
+}
+
+func (p *Postgres) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	res, err := p.DB.ExecContext(ctx, "DELETE FROM questions WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"html"
+	"interview-prep/models"
+	"strings"
+)
+
+// ts_headline marks matches with these private-use characters rather than
+// tags, so the text around them can be escaped before they become <mark>.
+const (
+	markStart = "\uE000"
+	markStop  = "\uE001"
+)
+
+const (
+	headlineMarks   = `StartSel="` + markStart + `", StopSel="` + markStop + `"`
+	headlineOptions = headlineMarks + `, MaxWords=35, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+// markHighlights HTML-escapes a headline and turns its match markers into
+// <mark> tags.
+func markHighlights(headline string) string {
+	return markReplacer.Replace(html.EscapeString(headline))
+}
+
+func (p *Postgres) SearchQuestions(ctx context.Context, search models.QuestionSearch) ([]models.SearchResult, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+questionColumns+`,
+			ts_rank(q.search_vector, query.tsq) AS rank,
+			ts_headline('english', q.question, query.tsq, $8),
+			ts_headline('english', COALESCE(q.answer, ''), query.tsq, $7),
+			ts_headline('english', COALESCE(q.context, ''), query.tsq, $7)
+		FROM questions q
+		JOIN categories c ON c.id = q.category_id
+		CROSS JOIN (SELECT websearch_to_tsquery('english', $1) AS tsq) query
+		WHERE q.search_vector @@ query.tsq
+			AND ($9 OR c.user_id = $2 OR EXISTS(SELECT 1 FROM category_permissions WHERE category_id = c.id AND user_id = $2 AND status = 'APPROVED'))
+			AND ($3 = 0 OR q.category_id = $3)
+			AND ($4 = '' OR q.difficulty = $4)
+			AND ($5 = 0 OR q.created_by = $5)
+			AND q.deleted_at IS NULL
+		ORDER BY rank DESC, q.id DESC
+		LIMIT $6
+	`, search.Query, search.UserID, search.CategoryID, search.Difficulty, search.CreatedBy, search.Limit, headlineOptions, headlineMarks+", HighlightAll=true", search.Admin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var r models.SearchResult
+		dest := append(questionDest(&r.Question), &r.Rank, &r.Highlights.Question, &r.Highlights.Answer, &r.Highlights.Context)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r.Highlights.Question = markHighlights(r.Highlights.Question)
+		r.Highlights.Answer = markHighlights(r.Highlights.Answer)
+		r.Highlights.Context = markHighlights(r.Highlights.Context)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
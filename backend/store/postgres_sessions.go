@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+func (p *Postgres) CreateSession(ctx context.Context, s *models.Session, tokenHash string) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO sessions (id, user_id, expires_at) VALUES ($1, $2, $3) RETURNING created_at",
+		s.ID, s.UserID, s.ExpiresAt,
+	).Scan(&s.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		s.ID, tokenHash, s.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var tokenID int
+	var usedAt sql.NullTime
+	var tokenExpiresAt time.Time
+	var suspended bool
+	var s models.Session
+	err = tx.QueryRowContext(ctx, `
+		SELECT rt.id, rt.used_at, rt.expires_at, s.id, s.user_id, s.created_at, s.expires_at, s.revoked_at, `+userSuspended+`
+		FROM refresh_tokens rt
+		JOIN sessions s ON rt.session_id = s.id
+		JOIN users u ON s.user_id = u.id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s
+	`, oldHash).Scan(&tokenID, &usedAt, &tokenExpiresAt, &s.ID, &s.UserID, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt, &suspended)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if s.RevokedAt != nil || !s.ExpiresAt.After(now) || !tokenExpiresAt.After(now) {
+		return nil, ErrSessionInactive
+	}
+	if suspended {
+		return nil, ErrSuspended
+	}
+
+	if usedAt.Valid {
+		// Someone is replaying a rotated token: assume it leaked and end
+		// the session for every holder.
+		if _, err := tx.ExecContext(ctx, "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1", s.ID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1", tokenID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		s.ID, newHash, expiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE sessions SET expires_at = $1 WHERE id = $2", expiresAt, s.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.ExpiresAt = expiresAt
+	return &s, nil
+}
+
+// userSuspended is true for rows of "users u" whose suspension is in force.
+const userSuspended = "(u.suspended_at IS NOT NULL AND (u.suspended_until IS NULL OR u.suspended_until > CURRENT_TIMESTAMP))"
+
+func (p *Postgres) SessionActive(ctx context.Context, id string) (bool, error) {
+	var active, suspended bool
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP, `+userSuspended+`
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1
+	`, id).Scan(&active, &suspended)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if active && suspended {
+		return false, ErrSuspended
+	}
+	return active, nil
+}
+
+func (p *Postgres) RevokeSession(ctx context.Context, id string) error {
+	return expectRow(p.DB.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL", id,
+	))
+}
+
+func (p *Postgres) RevokeUserSessions(ctx context.Context, userID int) error {
+	_, err := p.DB.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", userID,
+	)
+	return err
+}
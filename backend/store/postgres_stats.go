@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"time"
+)
+
+// The stats queries read the rollup tables that the study_events trigger
+// maintains, never the log itself.
+
+func (p *Postgres) RecordEvent(ctx context.Context, e *models.StudyEvent) error {
+	return p.DB.QueryRowContext(ctx, `
+		INSERT INTO study_events (user_id, type, question_id, category_id, interview_id, difficulty, grade, correct)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, 0), NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at
+	`, e.UserID, e.Type, e.QuestionID, e.CategoryID, e.InterviewID, e.Difficulty, e.Grade, e.Correct,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (p *Postgres) ActivityTotals(ctx context.Context, userID int) (*models.ActivityTotals, error) {
+	var t models.ActivityTotals
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(viewed), 0), COALESCE(SUM(answered), 0), COALESCE(SUM(graded), 0),
+			COALESCE(SUM(interviews_completed), 0), COUNT(*)
+		FROM user_daily_stats
+		WHERE user_id = $1
+	`, userID).Scan(&t.Viewed, &t.Answered, &t.Graded, &t.InterviewsCompleted, &t.ActiveDays)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) Streak(ctx context.Context, userID int, today time.Time) (*models.Streak, error) {
+	// Consecutive days share day - row_number, which groups them into runs
+	var s models.Streak
+	var lastActive sql.NullTime
+	err := p.DB.QueryRowContext(ctx, `
+		WITH runs AS (
+			SELECT MAX(day) AS last_day, COUNT(*) AS length
+			FROM (
+				SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day))::int AS run
+				FROM user_daily_stats
+				WHERE user_id = $1
+			) days
+			GROUP BY run
+		)
+		SELECT
+			COALESCE((SELECT length FROM runs WHERE last_day >= $2::date - 1 ORDER BY last_day DESC LIMIT 1), 0),
+			COALESCE(MAX(length), 0),
+			MAX(last_day)
+		FROM runs
+	`, userID, today.UTC().Format("2006-01-02")).Scan(&s.Current, &s.Longest, &lastActive)
+	if err != nil {
+		return nil, err
+	}
+	if lastActive.Valid {
+		s.LastActive = &lastActive.Time
+	}
+	return &s, nil
+}
+
+func (p *Postgres) Mastery(ctx context.Context, userID, matureDays int) ([]models.MasteryStats, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT q.category_id, c.name, COALESCE(q.difficulty, ''),
+			COUNT(*), COUNT(*) FILTER (WHERE r.interval_days >= $2)
+		FROM review_states r
+		JOIN questions q ON q.id = r.question_id
+		JOIN categories c ON c.id = q.category_id
+		WHERE r.user_id = $1 AND q.deleted_at IS NULL
+		GROUP BY q.category_id, c.name, q.difficulty
+		ORDER BY c.name, q.difficulty
+	`, userID, matureDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mastery := []models.MasteryStats{}
+	for rows.Next() {
+		var m models.MasteryStats
+		if err := rows.Scan(&m.CategoryID, &m.CategoryName, &m.Difficulty, &m.Reviewed, &m.Mastered); err != nil {
+			return nil, err
+		}
+		mastery = append(mastery, m)
+	}
+	return mastery, rows.Err()
+}
+
+func (p *Postgres) Accuracy(ctx context.Context, userID int, bucket string, since time.Time) ([]models.AccuracyBucket, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT date_trunc($2, day::timestamp), SUM(attempts), SUM(correct)
+		FROM user_daily_stats
+		WHERE user_id = $1 AND day >= $3::date AND attempts > 0
+		GROUP BY 1
+		ORDER BY 1
+	`, userID, bucket, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []models.AccuracyBucket{}
+	for rows.Next() {
+		var b models.AccuracyBucket
+		if err := rows.Scan(&b.Start, &b.Attempts, &b.Correct); err != nil {
+			return nil, err
+		}
+		b.Accuracy = float64(b.Correct) / float64(b.Attempts)
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (p *Postgres) WeakestCategories(ctx context.Context, userID, minAttempts, limit int) ([]models.CategoryAccuracy, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT s.category_id, c.name, s.attempts, s.correct
+		FROM user_category_stats s
+		JOIN categories c ON c.id = s.category_id
+		WHERE s.user_id = $1 AND s.attempts >= GREATEST($2, 1)
+		ORDER BY s.correct::float / s.attempts, s.attempts DESC, s.category_id
+		LIMIT $3
+	`, userID, minAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weakest := []models.CategoryAccuracy{}
+	for rows.Next() {
+		var a models.CategoryAccuracy
+		if err := rows.Scan(&a.CategoryID, &a.CategoryName, &a.Attempts, &a.Correct); err != nil {
+			return nil, err
+		}
+		a.Accuracy = float64(a.Correct) / float64(a.Attempts)
+		weakest = append(weakest, a)
+	}
+	return weakest, rows.Err()
+}
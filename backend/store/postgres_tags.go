@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"interview-prep/models"
+	"strings"
+)
+
+const tagColumns = `t.id, t.category_id, t.name, COALESCE(t.created_by, 0), t.created_at,
+	(SELECT COUNT(*) FROM question_tags qt JOIN questions q ON q.id = qt.question_id WHERE qt.tag_id = t.id AND q.deleted_at IS NULL)`
+
+func tagDest(t *models.Tag) []any {
+	return []any{&t.ID, &t.CategoryID, &t.Name, &t.CreatedBy, &t.CreatedAt, &t.QuestionCount}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (p *Postgres) ListTags(ctx context.Context, q models.TagQuery) ([]models.Tag, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+tagColumns+`
+		FROM tags t
+		JOIN categories c ON c.id = t.category_id
+		WHERE ($1 = 0 OR c.user_id = $1 OR EXISTS(SELECT 1 FROM category_permissions WHERE category_id = c.id AND user_id = $1 AND status = 'APPROVED'))
+			AND ($2 = 0 OR t.category_id = $2)
+			AND t.name LIKE $3
+		ORDER BY t.name, t.category_id
+	`, q.UserID, q.CategoryID, likeEscaper.Replace(q.Prefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(tagDest(&t)...); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (p *Postgres) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	var t models.Tag
+	err := p.DB.QueryRowContext(ctx, "SELECT "+tagColumns+" FROM tags t WHERE t.id = $1", id).Scan(tagDest(&t)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) CreateTag(ctx context.Context, t *models.Tag) error {
+	err := p.DB.QueryRowContext(ctx,
+		"INSERT INTO tags (category_id, name, created_by) VALUES ($1, $2, NULLIF($3, 0)) RETURNING id, created_at",
+		t.CategoryID, t.Name, t.CreatedBy,
+	).Scan(&t.ID, &t.CreatedAt)
+	return constraintError(err)
+}
+
+func (p *Postgres) RenameTag(ctx context.Context, id int, name string) error {
+	err := expectRow(p.DB.ExecContext(ctx, "UPDATE tags SET name = $2 WHERE id = $1", id, name))
+	return constraintError(err)
+}
+
+func (p *Postgres) DeleteTag(ctx context.Context, id int) error {
+	return expectRow(p.DB.ExecContext(ctx, "DELETE FROM tags WHERE id = $1", id))
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"interview-prep/models"
+	"testing"
+)
+
+func TestHighlightTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{"plain", "Explain goroutines and channels", []string{"goroutine"}, "Explain <mark>goroutines</mark> and channels"},
+		{"every match", "Channels: buffered channel", []string{"channel"}, "<mark>Channels</mark>: buffered <mark>channel</mark>"},
+		{"no match", "Explain maps", []string{"slice"}, "Explain maps"},
+		{"markup is escaped", `<script>alert("x")</script> goroutine`, []string{"goroutine"},
+			`&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>goroutine</mark>`},
+		{"matched word is escaped in its context", "a&b <b>defer</b>", []string{"defer"},
+			"a&amp;b &lt;b&gt;<mark>defer</mark>&lt;/b&gt;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightTerms(tt.text, tt.terms); got != tt.want {
+				t.Errorf("highlightTerms(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkHighlights(t *testing.T) {
+	tests := []struct {
+		headline string
+		want     string
+	}{
+		{"Explain " + markStart + "goroutines" + markStop + " briefly", "Explain <mark>goroutines</mark> briefly"},
+		{`<img src=x onerror="alert(1)"> ` + markStart + "defer" + markStop, `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>defer</mark>`},
+		{"no matches & all", "no matches &amp; all"},
+	}
+	for _, tt := range tests {
+		if got := markHighlights(tt.headline); got != tt.want {
+			t.Errorf("markHighlights(%q) = %q, want %q", tt.headline, got, tt.want)
+		}
+	}
+}
+
+func TestMemorySearchQuestions(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	owner := &models.User{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Phone: "1"}
+	if err := m.CreateUser(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+	stranger := &models.User{FirstName: "Eve", LastName: "Smith", Email: "eve@example.com", Phone: "2"}
+	if err := m.CreateUser(ctx, stranger); err != nil {
+		t.Fatal(err)
+	}
+	cat := &models.Category{Name: "Go", UserID: owner.ID}
+	if err := m.CreateCategory(ctx, cat); err != nil {
+		t.Fatal(err)
+	}
+	add := func(question, answer, context string) int {
+		q := &models.Question{CategoryID: cat.ID, Question: question, Answer: answer, Context: context, Difficulty: "Medium", CreatedBy: owner.ID}
+		if err := m.CreateQuestion(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+		return q.ID
+	}
+	inContext := add("What is a map?", "A hash table", "Often compared with channels")
+	inAnswer := add("How do goroutines talk?", "Over channels", "")
+	inQuestion := add("What are channels?", "Typed pipes", "")
+	alsoInQuestion := add("Closing channels", "Only senders close", "")
+	add("What is a slice?", "A view of an array", "")
+	excluded := add("Buffered channels", "Have a capacity", "Unlike a mutex")
+
+	search := func(query string, userID int) []models.SearchResult {
+		results, err := m.SearchQuestions(ctx, models.QuestionSearch{Query: query, UserID: userID, Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	t.Run("ranks question over answer over context", func(t *testing.T) {
+		results := search("channels -mutex", owner.ID)
+		// Equal ranks fall back to the newest question first
+		want := []int{alsoInQuestion, inQuestion, inAnswer, inContext}
+		if len(results) != len(want) {
+			t.Fatalf("got %d results, want %d", len(results), len(want))
+		}
+		for i, r := range results {
+			if r.Question.ID != want[i] {
+				t.Errorf("result %d is question %d, want %d", i, r.Question.ID, want[i])
+			}
+			if r.Question.ID == excluded {
+				t.Errorf("question %d matches an excluded term", excluded)
+			}
+		}
+		if i := 1; results[i].Highlights.Question != "What are <mark>channels</mark>?" {
+			t.Errorf("question highlight = %q", results[i].Highlights.Question)
+		}
+		if i := 2; results[i].Highlights.Answer != "Over <mark>channels</mark>" {
+			t.Errorf("answer highlight = %q", results[i].Highlights.Answer)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		results, err := m.SearchQuestions(ctx, models.QuestionSearch{Query: "channels", UserID: owner.ID, Limit: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 2 {
+			t.Errorf("got %d results, want 2", len(results))
+		}
+	})
+
+	t.Run("hidden from non-members", func(t *testing.T) {
+		if results := search("channels", stranger.ID); len(results) != 0 {
+			t.Errorf("stranger got %d results, want none", len(results))
+		}
+	})
+
+	t.Run("stop words only", func(t *testing.T) {
+		if results := search("what is the", owner.ID); len(results) != 0 {
+			t.Errorf("got %d results, want none", len(results))
+		}
+	})
+}
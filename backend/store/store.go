@@ -0,0 +1,279 @@
+// Package store is the persistence layer behind the HTTP handlers. Every
+// store has a Postgres implementation for production and an in-memory one
+// for tests that should not need a database.
+package store
+
+import (
+	"context"
+	"errors"
+	"interview-prep/models"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist, or
+	// when a write refers to one that does not.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write would violate a uniqueness rule.
+	ErrConflict = errors.New("already exists")
+	// ErrSessionInactive is returned for refresh tokens whose session has
+	// been revoked or has expired.
+	ErrSessionInactive = errors.New("session revoked or expired")
+	// ErrLastAdmin is returned for changes that would leave no admin.
+	ErrLastAdmin = errors.New("last admin")
+	// ErrSuspended is returned for sessions and refresh tokens of a
+	// suspended user.
+	ErrSuspended = errors.New("account suspended")
+	// ErrRefreshTokenReused is returned when an already rotated refresh
+	// token is presented again; the session is revoked as a side effect.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+type CategoryStore interface {
+	// ListCategories returns a page of categories annotated with the
+	// caller's permission and request status. It honours the CreatedBy and
+	// HasPermission filters.
+	ListCategories(ctx context.Context, q models.ListQuery) ([]models.Category, error)
+	GetCategory(ctx context.Context, id int) (*models.Category, error)
+	CreateCategory(ctx context.Context, cat *models.Category) error
+	DeleteCategory(ctx context.Context, id int) error
+	// TransferCategory makes toUserID the owner. The previous owner stays
+	// on as an editor.
+	TransferCategory(ctx context.Context, id, toUserID int) error
+}
+
+type QuestionStore interface {
+	// ListQuestions returns a page of questions. It honours the CategoryID,
+	// Difficulty, CreatedBy and Tags filters and, when UserID is set and
+	// Admin isn't, leaves out categories that user has no role on.
+	ListQuestions(ctx context.Context, q models.ListQuery) ([]models.Question, error)
+	GetQuestion(ctx context.Context, id int) (*models.Question, error)
+	// SearchQuestions runs a full-text search over question, answer and
+	// context within the categories the searching user has permission on,
+	// or all of them for an admin, best match first.
+	SearchQuestions(ctx context.Context, search models.QuestionSearch) ([]models.SearchResult, error)
+	// CreateQuestion stores q along with its first revision. Tags the
+	// category doesn't have yet are created.
+	CreateQuestion(ctx context.Context, q *models.Question) error
+	// ImportQuestions creates all of questions in one transaction, filling
+	// in their ids; if any insert fails none are kept.
+	ImportQuestions(ctx context.Context, questions []models.Question) error
+	// UpdateQuestion saves q's content and records it as a new revision
+	// by editorID. Non-nil Tags replace the question's tags, creating any
+	// the category doesn't have yet.
+	UpdateQuestion(ctx context.Context, q *models.Question, editorID int) error
+	// DeleteQuestion moves a question to the trash. Trashed questions are
+	// left out of every other query until restored.
+	DeleteQuestion(ctx context.Context, id, userID int) error
+
+	// ListRevisions returns a question's revisions, newest first.
+	ListRevisions(ctx context.Context, questionID int) ([]models.QuestionRevision, error)
+	// RestoreRevision copies revision rev back onto the question, recording
+	// the result as a new revision by userID.
+	RestoreRevision(ctx context.Context, questionID, rev, userID int) (*models.Question, error)
+
+	// ListTrash returns questions deleted since the given time, most
+	// recently deleted first. A userID limits it to categories that user has
+	// a role on and a categoryID to one category; 0 leaves either unset.
+	ListTrash(ctx context.Context, userID, categoryID int, since time.Time) ([]models.TrashedQuestion, error)
+	GetTrashedQuestion(ctx context.Context, id int, since time.Time) (*models.TrashedQuestion, error)
+	// RestoreQuestion takes a question deleted since the given time out of
+	// the trash.
+	RestoreQuestion(ctx context.Context, id int, since time.Time) error
+	// PurgeTrash permanently removes questions deleted before the given
+	// time and returns how many there were.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TagStore interface {
+	// ListTags returns tags sorted by name, each with the number of
+	// questions carrying it, not counting trashed ones.
+	ListTags(ctx context.Context, q models.TagQuery) ([]models.Tag, error)
+	GetTag(ctx context.Context, id int) (*models.Tag, error)
+	// CreateTag returns ErrConflict if the category already has a tag with
+	// that name.
+	CreateTag(ctx context.Context, t *models.Tag) error
+	// RenameTag returns ErrConflict if the category already has a tag with
+	// the new name.
+	RenameTag(ctx context.Context, id int, name string) error
+	// DeleteTag removes the tag from every question carrying it.
+	DeleteTag(ctx context.Context, id int) error
+}
+
+type PermissionStore interface {
+	// CategoryRole returns userID's role on the category: owner for its
+	// creator, the granted role for approved members and "" for everyone
+	// else. It returns ErrNotFound if the category doesn't exist.
+	CategoryRole(ctx context.Context, categoryID, userID int) (string, error)
+	CreateRequest(ctx context.Context, categoryID, userID int) error
+	GetRequest(ctx context.Context, id int) (*models.AccessRequest, error)
+	ListPendingRequests(ctx context.Context, categoryID int) ([]models.AccessRequest, error)
+	// UpdateRequestStatus sets a request's status and the role it grants.
+	UpdateRequestStatus(ctx context.Context, id int, status, role string) error
+	// ListMembers returns the approved members of a category.
+	ListMembers(ctx context.Context, categoryID int) ([]models.AccessRequest, error)
+	// SetMemberRole makes userID an approved member with role, whether or
+	// not they had asked for access. It returns ErrNotFound if the user
+	// doesn't exist.
+	SetMemberRole(ctx context.Context, categoryID, userID int, role string) error
+	RemoveMember(ctx context.Context, categoryID, userID int) error
+}
+
+type UserStore interface {
+	CreateUser(ctx context.Context, u *models.User) error
+	EmailOrPhoneTaken(ctx context.Context, email, phone string) (bool, error)
+	GetUser(ctx context.Context, id int) (*models.User, error)
+	// GetUserByEmail includes the password hash for credential checks.
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// ListUsers returns a page of users. It honours the Search, Role and
+	// Status filters.
+	ListUsers(ctx context.Context, q models.ListQuery) ([]models.User, error)
+	// UpdateUser saves u's name, email, phone and email verification,
+	// revoking the user's sessions if it clears the verification. It
+	// returns ErrConflict if another user has the email or phone.
+	UpdateUser(ctx context.Context, u *models.User) error
+	// ChangePassword sets a new password hash and revokes every session
+	// of the user but keepSessionID.
+	ChangePassword(ctx context.Context, userID int, passwordHash, keepSessionID string) error
+	// UserSummary returns the user with the categories they own and their
+	// access requests and memberships on others.
+	UserSummary(ctx context.Context, userID int) (*models.UserSummary, error)
+}
+
+// AdminStore makes administrative changes to accounts, recording each in
+// the audit log in the same transaction. actorID 0 stands for the command
+// line.
+type AdminStore interface {
+	// CreateAdmin creates a verified admin account. It returns ErrConflict
+	// if the email is taken.
+	CreateAdmin(ctx context.Context, actorID int, u *models.User) error
+	// SetUserRole revokes the user's sessions so the new role applies at
+	// once. It returns ErrLastAdmin rather than demote the only admin.
+	SetUserRole(ctx context.Context, actorID, userID int, role string) error
+	// SetUserLocked locks or unlocks an account; locking revokes its
+	// sessions.
+	SetUserLocked(ctx context.Context, actorID, userID int, locked bool) error
+	// RequirePasswordReset blocks sign-in until the user resets their
+	// password and revokes their sessions.
+	RequirePasswordReset(ctx context.Context, actorID, userID int) error
+	// EditUser is UpdateUser on behalf of an admin, recording the fields
+	// that changed.
+	EditUser(ctx context.Context, actorID int, u *models.User) error
+	// SuspendUser suspends an account until until, or indefinitely when it
+	// is nil, and revokes their sessions.
+	SuspendUser(ctx context.Context, actorID, userID int, until *time.Time, reason string) error
+	UnsuspendUser(ctx context.Context, actorID, userID int) error
+	// DeleteUser deletes an account. The categories it owns are handed to
+	// reassignTo, or deleted with their questions when reassignTo is 0. It
+	// returns ErrNotFound if either user doesn't exist and ErrLastAdmin
+	// rather than delete the only admin.
+	DeleteUser(ctx context.Context, actorID, userID, reassignTo int) error
+	// ListAuditLog pages through the log, filtered by TargetUserID.
+	ListAuditLog(ctx context.Context, q models.ListQuery) ([]models.AuditEntry, error)
+}
+
+// AccountStore keeps the single-use tokens mailed to users, by hash.
+// Consuming a token marks it used; unknown, used and expired tokens are
+// ErrNotFound.
+type AccountStore interface {
+	// CreateAccountToken replaces the user's unused tokens for purpose.
+	CreateAccountToken(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error
+	// CheckAccountToken reports, without consuming it, whether a token
+	// for purpose is live.
+	CheckAccountToken(ctx context.Context, purpose, tokenHash string) error
+	// VerifyEmail consumes a verification token and marks its user's
+	// address verified.
+	VerifyEmail(ctx context.Context, tokenHash string) (*models.User, error)
+	// ResetPassword consumes a reset token, sets its user's password hash,
+	// clears a required reset and revokes all their sessions. The address
+	// counts as verified too, since the token was delivered to it.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*models.User, error)
+}
+
+type IdentityStore interface {
+	// SaveOIDCLogin remembers a sign-in until the provider redirects back.
+	SaveOIDCLogin(ctx context.Context, l *models.OIDCLogin) error
+	// TakeOIDCLogin returns and forgets the unexpired sign-in with
+	// stateHash, or ErrNotFound.
+	TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error)
+	// SignInWithIdentity returns the user linked to the identity. An
+	// identity seen for the first time is linked to the user with its
+	// email if the provider verified it, or else gets a new user, and
+	// created says so. It returns ErrConflict if the email belongs to a
+	// user the identity may not be linked to.
+	SignInWithIdentity(ctx context.Context, id models.ExternalIdentity) (user *models.User, created bool, err error)
+}
+
+type SessionStore interface {
+	// CreateSession starts a session and stores its first refresh token.
+	CreateSession(ctx context.Context, s *models.Session, tokenHash string) error
+	// RotateRefreshToken consumes the refresh token with oldHash and stores
+	// newHash as its successor, extending the session to expiresAt. While
+	// the user is suspended it returns ErrSuspended and keeps the token.
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error)
+	// SessionActive reports whether the session exists, is not revoked and
+	// has not expired. It returns ErrSuspended while the session's user is
+	// suspended.
+	SessionActive(ctx context.Context, id string) (bool, error)
+	RevokeSession(ctx context.Context, id string) error
+	RevokeUserSessions(ctx context.Context, userID int) error
+}
+
+type ReviewStore interface {
+	// ListDueReviews returns questions from categories userID has permission
+	// on that are due at now, most overdue first, followed by questions never
+	// reviewed. A categoryID of 0 covers every category.
+	ListDueReviews(ctx context.Context, userID, categoryID int, now time.Time, limit int) ([]models.DueReview, error)
+	GetReviewState(ctx context.Context, userID, questionID int) (*models.ReviewState, error)
+	SaveReviewState(ctx context.Context, s *models.ReviewState) error
+}
+
+type StatsStore interface {
+	// RecordEvent appends to the activity log. The rollups the other
+	// methods read are brought up to date with it.
+	RecordEvent(ctx context.Context, e *models.StudyEvent) error
+	ActivityTotals(ctx context.Context, userID int) (*models.ActivityTotals, error)
+	// Streak measures userID's runs of active days as of today.
+	Streak(ctx context.Context, userID int, today time.Time) (*models.Streak, error)
+	// Mastery groups the questions userID has reviewed by category and
+	// difficulty. Those whose review interval has reached matureDays count
+	// as mastered.
+	Mastery(ctx context.Context, userID, matureDays int) ([]models.MasteryStats, error)
+	// Accuracy returns userID's attempts per bucket from since on, oldest
+	// first. Buckets without attempts are left out.
+	Accuracy(ctx context.Context, userID int, bucket string, since time.Time) ([]models.AccuracyBucket, error)
+	// WeakestCategories returns up to limit categories where userID has made
+	// at least minAttempts attempts, lowest accuracy first.
+	WeakestCategories(ctx context.Context, userID, minAttempts, limit int) ([]models.CategoryAccuracy, error)
+}
+
+type InterviewStore interface {
+	// SampleQuestions draws up to limit random questions from categoryIDs,
+	// skipping exclude. An empty difficulty matches any.
+	SampleQuestions(ctx context.Context, categoryIDs []int, difficulty string, limit int, exclude []int) ([]models.Question, error)
+	// CreateInterview stores a session together with its questions.
+	CreateInterview(ctx context.Context, s *models.InterviewSession) error
+	// ListInterviews returns userID's sessions newest first, without questions.
+	ListInterviews(ctx context.Context, userID int) ([]models.InterviewSession, error)
+	// GetInterview returns a session with its questions in order.
+	GetInterview(ctx context.Context, id int) (*models.InterviewSession, error)
+	MarkInterviewQuestionServed(ctx context.Context, id int, at time.Time) error
+	SaveInterviewResponse(ctx context.Context, q *models.InterviewQuestion) error
+	CompleteInterview(ctx context.Context, id int, at time.Time) error
+}
+
+// Store is the union of every store; Postgres and Memory both implement it.
+type Store interface {
+	CategoryStore
+	QuestionStore
+	TagStore
+	PermissionStore
+	UserStore
+	AdminStore
+	AccountStore
+	IdentityStore
+	SessionStore
+	ReviewStore
+	InterviewStore
+	StatsStore
+}
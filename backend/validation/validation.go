@@ -0,0 +1,80 @@
+// Package validation checks values against their validate tags, naming
+// fields by their JSON names as clients know them. The API and the
+// importer share it, so a question is held to the same rules however it
+// arrives.
+package validation
+
+import (
+	"errors"
+	"interview-prep/apperr"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// Struct checks v's validate tags, returning what is wrong with each field
+// at fault. The error is only set when v can't be validated at all, as
+// when it isn't a struct.
+func Struct(v any) ([]apperr.FieldError, error) {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil, nil
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return nil, err
+	}
+	fields := make([]apperr.FieldError, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		fields[i] = apperr.FieldError{Field: fieldError.Field(), Message: fieldMessage(fieldError)}
+	}
+	return fields, nil
+}
+
+// Label names a field for the user, as in "First name" for first_name.
+func Label(field string) string {
+	label := strings.ReplaceAll(field, "_", " ")
+	if label != "" {
+		label = strings.ToUpper(label[:1]) + label[1:]
+	}
+	return label
+}
+
+// fieldMessage describes a failed rule for the user, as in "First name
+// must be at least 2 characters long".
+func fieldMessage(fieldError validator.FieldError) string {
+	label := Label(fieldError.Field())
+	unit := ""
+	if fieldError.Kind() == reflect.String {
+		unit = " characters long"
+	}
+
+	switch fieldError.Tag() {
+	case "required":
+		return label + " is required"
+	case "min":
+		return label + " must be at least " + fieldError.Param() + unit
+	case "max":
+		return label + " must be at most " + fieldError.Param() + unit
+	case "email":
+		return label + " must be a valid email address"
+	case "oneof":
+		return label + " must be one of " + strings.Join(strings.Fields(fieldError.Param()), ", ")
+	}
+	return label + " is invalid"
+}
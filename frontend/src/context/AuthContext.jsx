@@ -9,6 +9,7 @@ export const AuthProvider = ({ children }) => {
     const [user, setUser] = useState(null);
     const [loading, setLoading] = useState(true);
     const [token, setToken] = useState(localStorage.getItem('token'));
+    const [refreshToken, setRefreshToken] = useState(localStorage.getItem('refresh_token'));
 
     // Configure axios defaults
     useEffect(() => {
@@ -21,6 +22,52 @@ export const AuthProvider = ({ children }) => {
         }
     }, [token]);
 
+    useEffect(() => {
+        if (refreshToken) {
+            localStorage.setItem('refresh_token', refreshToken);
+        } else {
+            localStorage.removeItem('refresh_token');
+        }
+    }, [refreshToken]);
+
+    // Access tokens are short-lived: on a 401, trade the refresh token for a
+    // new pair once and replay the request.
+    useEffect(() => {
+        const interceptor = axios.interceptors.response.use(
+            (response) => response,
+            async (error) => {
+                const original = error.config;
+                const storedRefreshToken = localStorage.getItem('refresh_token');
+                if (
+                    error.response?.status !== 401 ||
+                    original._retried ||
+                    original.url.includes('/auth/') ||
+                    !storedRefreshToken
+                ) {
+                    return Promise.reject(error);
+                }
+                original._retried = true;
+
+                try {
+                    const response = await axios.post(`${API_URL}/auth/refresh`, {
+                        refresh_token: storedRefreshToken
+                    });
+                    const { token: newToken, refresh_token: newRefreshToken } = response.data;
+                    axios.defaults.headers.common['Authorization'] = `Bearer ${newToken}`;
+                    localStorage.setItem('refresh_token', newRefreshToken);
+                    setToken(newToken);
+                    setRefreshToken(newRefreshToken);
+                    original.headers['Authorization'] = `Bearer ${newToken}`;
+                    return axios(original);
+                } catch (refreshError) {
+                    clearSession();
+                    return Promise.reject(error);
+                }
+            }
+        );
+        return () => axios.interceptors.response.eject(interceptor);
+    }, []);
+
     // Load user from token on mount
     useEffect(() => {
         const loadUser = async () => {
@@ -41,26 +88,29 @@ export const AuthProvider = ({ children }) => {
         loadUser();
     }, []);
 
+    const startSession = ({ token: newToken, refresh_token: newRefreshToken, user: userData }) => {
+        setToken(newToken);
+        setRefreshToken(newRefreshToken);
+        setUser(userData);
+        localStorage.setItem('token', newToken);
+        localStorage.setItem('user', JSON.stringify(userData));
+    };
+
     const login = async (email, password) => {
         try {
             const response = await axios.post(`${API_URL}/login`, {
                 email,
                 password
             });
-
-            const { token: newToken, user: userData } = response.data;
-
-            setToken(newToken);
-            setUser(userData);
-            localStorage.setItem('token', newToken);
-            localStorage.setItem('user', JSON.stringify(userData));
+            startSession(response.data);
 
             return { success: true };
         } catch (error) {
             console.error('Login error:', error);
             return {
                 success: false,
-                error: error.response?.data?.error || 'Login failed'
+                error: error.response?.data?.detail || 'Login failed',
+                code: error.response?.data?.code
             };
         }
     };
@@ -72,39 +122,119 @@ export const AuthProvider = ({ children }) => {
                 password,
                 first_name: firstName,
                 last_name: lastName,
-                phone,
-                role: 'USER'  // Default role for new signups
+                phone
             });
 
-            const { token: newToken, user: userData } = response.data;
+            // No session yet: the account is used after verifying the email
+            return { success: true, message: response.data.message };
+        } catch (error) {
+            console.error('Signup error:', error);
+            return {
+                success: false,
+                error: error.response?.data?.detail || 'Signup failed'
+            };
+        }
+    };
 
-            setToken(newToken);
-            setUser(userData);
-            localStorage.setItem('token', newToken);
-            localStorage.setItem('user', JSON.stringify(userData));
+    // The account endpoints below answer with a message or an error only
+    const accountRequest = async (path, body, fallbackError) => {
+        try {
+            const response = await axios.post(`${API_URL}${path}`, body);
+            return { success: true, message: response.data.message, data: response.data };
+        } catch (error) {
+            return {
+                success: false,
+                error: error.response?.data?.detail || fallbackError
+            };
+        }
+    };
+
+    const verifyEmail = async (verificationToken) => {
+        const result = await accountRequest('/auth/verify-email', { token: verificationToken }, 'Verification failed');
+        if (result.success) {
+            startSession(result.data);
+        }
+        return result;
+    };
+
+    const resendVerification = (email) =>
+        accountRequest('/auth/resend-verification', { email }, 'Could not send a new link');
+
+    const forgotPassword = (email) =>
+        accountRequest('/auth/forgot-password', { email }, 'Could not send a reset link');
+
+    const resetPassword = (resetToken, password) =>
+        accountRequest('/auth/reset-password', { token: resetToken, password }, 'Password reset failed');
 
+    // Single sign-on: the provider sends the user back to /oidc/callback
+    // with a code and state, which the backend trades for a session.
+    const getOidcConfig = async () => {
+        try {
+            const response = await axios.get(`${API_URL}/auth/oidc`);
+            return response.data;
+        } catch (error) {
+            return { enabled: false };
+        }
+    };
+
+    const startOidcLogin = async () => {
+        try {
+            const response = await axios.get(`${API_URL}/auth/oidc/start`);
+            window.location.assign(response.data.authorization_url);
             return { success: true };
         } catch (error) {
-            console.error('Signup error:', error);
             return {
                 success: false,
-                error: error.response?.data?.error || 'Signup failed'
+                error: error.response?.data?.detail || 'Single sign-on is unavailable'
             };
         }
     };
 
-    const logout = () => {
+    const completeOidcLogin = async (code, state) => {
+        try {
+            const response = await axios.post(`${API_URL}/auth/oidc/callback`, { code, state });
+            startSession(response.data);
+            return { success: true };
+        } catch (error) {
+            return {
+                success: false,
+                error: error.response?.data?.detail || 'Sign-in failed',
+                code: error.response?.data?.code
+            };
+        }
+    };
+
+    const clearSession = () => {
         setToken(null);
+        setRefreshToken(null);
         setUser(null);
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         localStorage.removeItem('user');
     };
 
+    const logout = async () => {
+        try {
+            // Revoke the session server-side so the tokens stop working
+            await axios.post(`${API_URL}/auth/logout`);
+        } catch (error) {
+            console.error('Logout error:', error);
+        }
+        clearSession();
+    };
+
     const value = {
         user,
         token,
         login,
         signup,
+        verifyEmail,
+        resendVerification,
+        forgotPassword,
+        resetPassword,
+        getOidcConfig,
+        startOidcLogin,
+        completeOidcLogin,
         logout,
         loading,
         isAuthenticated: !!user
@@ -0,0 +1,89 @@
+import React, { useState } from 'react';
+import { Link } from 'react-router-dom';
+import { useAuth } from '../context/AuthContext';
+
+const ForgotPassword = () => {
+    const [email, setEmail] = useState('');
+    const [error, setError] = useState('');
+    const [message, setMessage] = useState('');
+    const [loading, setLoading] = useState(false);
+    const { forgotPassword } = useAuth();
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+        setError('');
+        setLoading(true);
+
+        const result = await forgotPassword(email);
+
+        if (result.success) {
+            setMessage(result.message);
+        } else {
+            setError(result.error);
+        }
+
+        setLoading(false);
+    };
+
+    return (
+        <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
+            <div className="max-w-md w-full space-y-8 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800">
+                <div>
+                    <h2 className="text-center text-3xl font-bold text-white tracking-tight">
+                        Forgot Password
+                    </h2>
+                    <p className="mt-2 text-center text-sm text-gray-400">
+                        We'll email you a link to choose a new one
+                    </p>
+                </div>
+
+                {message ? (
+                    <div className="bg-green-900/20 border-l-4 border-green-500 p-4 rounded-r-lg">
+                        <p className="text-sm text-green-400">{message}</p>
+                    </div>
+                ) : (
+                    <form className="space-y-6" onSubmit={handleSubmit}>
+                        {error && (
+                            <div className="bg-red-900/20 border-l-4 border-red-500 p-4 rounded-r-lg">
+                                <p className="text-sm text-red-400">{error}</p>
+                            </div>
+                        )}
+
+                        <div>
+                            <label htmlFor="email" className="block text-sm font-medium text-gray-300 mb-1.5">
+                                Email Address
+                            </label>
+                            <input
+                                id="email"
+                                name="email"
+                                type="email"
+                                autoComplete="email"
+                                required
+                                value={email}
+                                onChange={(e) => setEmail(e.target.value)}
+                                className="appearance-none block w-full px-4 py-3 border border-neutral-700 bg-black placeholder-neutral-600 text-white rounded-xl focus:outline-none focus:ring-2 focus:ring-green-500 focus:border-transparent transition-all"
+                                placeholder="you@example.com"
+                            />
+                        </div>
+
+                        <button
+                            type="submit"
+                            disabled={loading}
+                            className="w-full flex justify-center py-3.5 px-4 border border-transparent text-sm font-bold rounded-xl text-black bg-green-600 hover:bg-green-500 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-green-500 transition-all duration-200 disabled:opacity-70 disabled:cursor-not-allowed shadow-lg shadow-green-900/20"
+                        >
+                            {loading ? 'Sending...' : 'Send Reset Link'}
+                        </button>
+                    </form>
+                )}
+
+                <div className="text-center">
+                    <Link to="/login" className="text-sm font-semibold text-green-500 hover:text-green-400 transition">
+                        Back to sign in
+                    </Link>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default ForgotPassword;
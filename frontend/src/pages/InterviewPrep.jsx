@@ -8,6 +8,7 @@ function InterviewPrep() {
     const { user } = useAuth();
     const [categories, setCategories] = useState([]);
     const [questions, setQuestions] = useState([]);
+    const [nextCursor, setNextCursor] = useState('');
     const [selectedCategory, setSelectedCategory] = useState('');
     const [showQuestionForm, setShowQuestionForm] = useState(false);
     const [showCategoryForm, setShowCategoryForm] = useState(false);
@@ -33,32 +34,37 @@ function InterviewPrep() {
         fetchQuestions(selectedCategory);
     }, [selectedCategory]);
 
+    // The sidebar needs every category, so follow the cursor to the end
     const fetchCategories = async () => {
         try {
-            const res = await axios.get(`${API_URL}/categories`);
-            if (Array.isArray(res.data)) {
-                setCategories(res.data);
-            } else {
-                setCategories([]);
-            }
+            let all = [];
+            let cursor = '';
+            do {
+                const res = await axios.get(`${API_URL}/categories`, { params: { limit: 100, cursor: cursor || undefined } });
+                all = all.concat(res.data.items || []);
+                cursor = res.data.next_cursor;
+            } while (cursor);
+            setCategories(all);
         } catch (err) {
             console.error(err);
             setCategories([]);
         }
     };
 
-    const fetchQuestions = async (catId = '') => {
+    const fetchQuestions = async (catId = '', cursor = '') => {
         try {
-            const url = catId ? `${API_URL}/questions?category_id=${catId}` : `${API_URL}/questions`;
-            const res = await axios.get(url);
-            if (Array.isArray(res.data)) {
-                setQuestions(res.data);
-            } else {
-                setQuestions([]);
-            }
+            const res = await axios.get(`${API_URL}/questions`, {
+                params: { category_id: catId || undefined, cursor: cursor || undefined }
+            });
+            const items = res.data.items || [];
+            setQuestions(prev => (cursor ? prev.concat(items) : items));
+            setNextCursor(res.data.next_cursor || '');
         } catch (err) {
             console.error(err);
-            setQuestions([]);
+            if (!cursor) {
+                setQuestions([]);
+                setNextCursor('');
+            }
         }
     };
 
@@ -71,7 +77,7 @@ function InterviewPrep() {
             fetchCategories();
         } catch (err) {
             console.error(err);
-            alert(err.response?.data?.error || 'Error creating category');
+            alert(err.response?.data?.detail || 'Error creating category');
         }
     };
 
@@ -115,7 +121,7 @@ function InterviewPrep() {
             fetchQuestions();
         } catch (err) {
             console.error('Delete error:', err);
-            alert(err.response?.data?.error || 'Error deleting category');
+            alert(err.response?.data?.detail || 'Error deleting category');
         }
     };
 
@@ -125,7 +131,7 @@ function InterviewPrep() {
             alert('Request sent! Waiting for approval.');
             fetchCategories();
         } catch (err) {
-            alert(err.response?.data?.error || 'Error sending request');
+            alert(err.response?.data?.detail || 'Error sending request');
         }
     };
 
@@ -152,6 +158,14 @@ function InterviewPrep() {
     };
 
     const currentCategory = categories.find(c => c.id.toString() === selectedCategory);
+    const myId = user?.id || user?.user_id;
+    const canManage = ['owner', 'editor'].includes(currentCategory?.role);
+
+    // Editors and owners may change any question, contributors their own
+    const canEditQuestion = (q) => {
+        const role = categories.find(c => c.id === q.category_id)?.role;
+        return role === 'owner' || role === 'editor' || (role === 'contributor' && String(q.created_by) === String(myId));
+    };
 
 
 
@@ -219,7 +233,7 @@ function InterviewPrep() {
                     </div>
 
                     {
-                        selectedCategory && String(currentCategory?.user_id) === String(user?.id || user?.user_id) && (
+                        selectedCategory && canManage && (
                             <div className="p-4 border-t border-neutral-800 bg-neutral-900/50 flex flex-col gap-2">
                                 <button
                                     onClick={fetchRequests}
@@ -230,6 +244,7 @@ function InterviewPrep() {
                                     </svg>
                                     Manage Requests
                                 </button>
+                                {currentCategory?.role === 'owner' && (
                                 <button
                                     onClick={handleDeleteCategory}
                                     className="w-full flex items-center justify-center gap-2 text-red-500 hover:text-red-400 text-xs font-medium px-3 py-2 rounded-lg hover:bg-red-500/10 transition-colors"
@@ -239,6 +254,7 @@ function InterviewPrep() {
                                     </svg>
                                     Delete Category
                                 </button>
+                                )}
                             </div>
                         )
                     }
@@ -253,7 +269,7 @@ function InterviewPrep() {
                             ? categories.find(c => c.id.toString() === selectedCategory)?.name
                             : 'All Questions'}
                         <span className="ml-3 text-sm font-normal text-gray-500 bg-neutral-900 px-2.5 py-0.5 rounded-full border border-neutral-800">
-                            {questions.length}
+                            {questions.length}{nextCursor && '+'}
                         </span>
                     </h2>
                     {currentCategory?.has_permission ? (
@@ -371,6 +387,7 @@ function InterviewPrep() {
                                         {new Date(q.created_at || Date.now()).toLocaleDateString()}
                                     </span>
                                 </div>
+                                {canEditQuestion(q) && (
                                 <button
                                     onClick={() => handleDeleteQuestion(q.id)}
                                     className="text-gray-600 hover:text-red-500 opacity-0 group-hover:opacity-100 transition-all p-1 hover:bg-red-900/20 rounded"
@@ -380,6 +397,7 @@ function InterviewPrep() {
                                         <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16" />
                                     </svg>
                                 </button>
+                                )}
                             </div>
 
                             <h3 className="text-lg font-semibold text-gray-100 mb-3 leading-snug">{q.question}</h3>
@@ -399,6 +417,15 @@ function InterviewPrep() {
                         </div>
                     ))}
 
+                    {nextCursor && (
+                        <button
+                            onClick={() => fetchQuestions(selectedCategory, nextCursor)}
+                            className="w-full py-2 rounded-lg border border-neutral-800 text-sm text-gray-400 hover:text-white hover:border-neutral-700 transition-colors"
+                        >
+                            Load more
+                        </button>
+                    )}
+
                     {questions.length === 0 && (
                         <div className="text-center py-16 bg-neutral-900 rounded-xl border border-dashed border-neutral-800">
                             <div className="bg-neutral-800 w-16 h-16 rounded-full flex items-center justify-center mx-auto mb-4">
@@ -1,4 +1,4 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 
@@ -7,12 +7,20 @@ const Login = () => {
     const [password, setPassword] = useState('');
     const [error, setError] = useState('');
     const [loading, setLoading] = useState(false);
-    const { login } = useAuth();
+    const [unverified, setUnverified] = useState(false);
+    const [notice, setNotice] = useState('');
+    const [oidc, setOidc] = useState({ enabled: false });
+    const { login, resendVerification, getOidcConfig, startOidcLogin } = useAuth();
     const navigate = useNavigate();
 
+    useEffect(() => {
+        getOidcConfig().then(setOidc);
+    }, []);
+
     const handleSubmit = async (e) => {
         e.preventDefault();
         setError('');
+        setNotice('');
         setLoading(true);
 
         const result = await login(email, password);
@@ -21,11 +29,32 @@ const Login = () => {
             navigate('/');
         } else {
             setError(result.error);
+            setUnverified(result.code === 'email_unverified');
         }
 
         setLoading(false);
     };
 
+    const handleOidc = async () => {
+        setError('');
+        setNotice('');
+        const result = await startOidcLogin();
+        if (!result.success) {
+            setError(result.error);
+        }
+    };
+
+    const handleResend = async () => {
+        const result = await resendVerification(email);
+        if (result.success) {
+            setError('');
+            setUnverified(false);
+            setNotice(result.message);
+        } else {
+            setError(result.error);
+        }
+    };
+
     return (
         <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
             <div className="max-w-md w-full space-y-8 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800">
@@ -54,11 +83,26 @@ const Login = () => {
                                 </div>
                                 <div className="ml-3">
                                     <p className="text-sm text-red-400">{error}</p>
+                                    {unverified && (
+                                        <button
+                                            type="button"
+                                            onClick={handleResend}
+                                            className="mt-2 text-sm font-semibold text-green-500 hover:text-green-400 transition"
+                                        >
+                                            Send a new verification link
+                                        </button>
+                                    )}
                                 </div>
                             </div>
                         </div>
                     )}
 
+                    {notice && (
+                        <div className="bg-green-900/20 border-l-4 border-green-500 p-4 rounded-r-lg">
+                            <p className="text-sm text-green-400">{notice}</p>
+                        </div>
+                    )}
+
                     <div className="space-y-5">
                         <div>
                             <label htmlFor="email" className="block text-sm font-medium text-gray-300 mb-1.5">
@@ -78,9 +122,14 @@ const Login = () => {
                         </div>
 
                         <div>
-                            <label htmlFor="password" className="block text-sm font-medium text-gray-300 mb-1.5">
-                                Password
-                            </label>
+                            <div className="flex justify-between items-center mb-1.5">
+                                <label htmlFor="password" className="block text-sm font-medium text-gray-300">
+                                    Password
+                                </label>
+                                <Link to="/forgot-password" className="text-sm text-green-500 hover:text-green-400 transition">
+                                    Forgot password?
+                                </Link>
+                            </div>
                             <input
                                 id="password"
                                 name="password"
@@ -115,6 +164,16 @@ const Login = () => {
                         </button>
                     </div>
 
+                    {oidc.enabled && (
+                        <button
+                            type="button"
+                            onClick={handleOidc}
+                            className="w-full flex justify-center py-3.5 px-4 border border-neutral-700 text-sm font-semibold rounded-xl text-white bg-black hover:bg-neutral-800 focus:outline-none focus:ring-2 focus:ring-green-500 transition-all duration-200"
+                        >
+                            Sign in with {oidc.name}
+                        </button>
+                    )}
+
                     <div className="text-center">
                         <p className="text-sm text-gray-400">
                             Don't have an account?{' '}
@@ -0,0 +1,57 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
+import { useAuth } from '../context/AuthContext';
+
+const OidcCallback = () => {
+    const [searchParams] = useSearchParams();
+    const [error, setError] = useState('');
+    const { completeOidcLogin } = useAuth();
+    const navigate = useNavigate();
+    // Codes and states are single-use, so don't send them twice under StrictMode
+    const attempted = useRef(false);
+
+    useEffect(() => {
+        if (attempted.current) {
+            return;
+        }
+        attempted.current = true;
+
+        const code = searchParams.get('code');
+        const state = searchParams.get('state');
+        if (searchParams.get('error')) {
+            setError(searchParams.get('error_description') || 'Sign-in was cancelled');
+            return;
+        }
+        if (!code || !state) {
+            setError('This sign-in link is incomplete');
+            return;
+        }
+        completeOidcLogin(code, state).then((result) => {
+            if (result.success) {
+                navigate('/', { replace: true });
+            } else {
+                setError(result.error);
+            }
+        });
+    }, []);
+
+    return (
+        <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
+            <div className="max-w-md w-full space-y-6 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800 text-center">
+                <h2 className="text-3xl font-bold text-white tracking-tight">
+                    {error ? 'Sign-in failed' : 'Signing you in...'}
+                </h2>
+                {error && (
+                    <>
+                        <p className="text-sm text-red-400">{error}</p>
+                        <Link to="/login" className="inline-block font-semibold text-green-500 hover:text-green-400 transition">
+                            Back to sign in
+                        </Link>
+                    </>
+                )}
+            </div>
+        </div>
+    );
+};
+
+export default OidcCallback;
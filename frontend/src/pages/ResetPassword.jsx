@@ -0,0 +1,122 @@
+import React, { useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { useAuth } from '../context/AuthContext';
+
+const ResetPassword = () => {
+    const [searchParams] = useSearchParams();
+    const [password, setPassword] = useState('');
+    const [confirmPassword, setConfirmPassword] = useState('');
+    const [error, setError] = useState('');
+    const [message, setMessage] = useState('');
+    const [loading, setLoading] = useState(false);
+    const { resetPassword } = useAuth();
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+        setError('');
+
+        if (password !== confirmPassword) {
+            setError('Passwords do not match');
+            return;
+        }
+
+        if (password.length < 6) {
+            setError('Password must be at least 6 characters long');
+            return;
+        }
+
+        setLoading(true);
+
+        const result = await resetPassword(searchParams.get('token') || '', password);
+
+        if (result.success) {
+            setMessage(result.message);
+        } else {
+            setError(result.error);
+        }
+
+        setLoading(false);
+    };
+
+    const inputClass = "appearance-none block w-full px-4 py-3 border border-neutral-700 bg-black placeholder-neutral-600 text-white rounded-xl focus:outline-none focus:ring-2 focus:ring-green-500 focus:border-transparent transition-all";
+
+    return (
+        <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
+            <div className="max-w-md w-full space-y-8 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800">
+                <div>
+                    <h2 className="text-center text-3xl font-bold text-white tracking-tight">
+                        Choose a New Password
+                    </h2>
+                    <p className="mt-2 text-center text-sm text-gray-400">
+                        You'll be signed out of every device
+                    </p>
+                </div>
+
+                {message ? (
+                    <div className="bg-green-900/20 border-l-4 border-green-500 p-4 rounded-r-lg">
+                        <p className="text-sm text-green-400">{message}</p>
+                    </div>
+                ) : (
+                    <form className="space-y-6" onSubmit={handleSubmit}>
+                        {error && (
+                            <div className="bg-red-900/20 border-l-4 border-red-500 p-4 rounded-r-lg">
+                                <p className="text-sm text-red-400">{error}</p>
+                            </div>
+                        )}
+
+                        <div>
+                            <label htmlFor="password" className="block text-sm font-medium text-gray-300 mb-1.5">
+                                New Password
+                            </label>
+                            <input
+                                id="password"
+                                name="password"
+                                type="password"
+                                autoComplete="new-password"
+                                required
+                                minLength="6"
+                                value={password}
+                                onChange={(e) => setPassword(e.target.value)}
+                                className={inputClass}
+                                placeholder="••••••••"
+                            />
+                        </div>
+
+                        <div>
+                            <label htmlFor="confirmPassword" className="block text-sm font-medium text-gray-300 mb-1.5">
+                                Confirm Password
+                            </label>
+                            <input
+                                id="confirmPassword"
+                                name="confirmPassword"
+                                type="password"
+                                autoComplete="new-password"
+                                required
+                                value={confirmPassword}
+                                onChange={(e) => setConfirmPassword(e.target.value)}
+                                className={inputClass}
+                                placeholder="••••••••"
+                            />
+                        </div>
+
+                        <button
+                            type="submit"
+                            disabled={loading}
+                            className="w-full flex justify-center py-3.5 px-4 border border-transparent text-sm font-bold rounded-xl text-black bg-green-600 hover:bg-green-500 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-green-500 transition-all duration-200 disabled:opacity-70 disabled:cursor-not-allowed shadow-lg shadow-green-900/20"
+                        >
+                            {loading ? 'Saving...' : 'Set Password'}
+                        </button>
+                    </form>
+                )}
+
+                <div className="text-center">
+                    <Link to="/login" className="text-sm font-semibold text-green-500 hover:text-green-400 transition">
+                        Back to sign in
+                    </Link>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default ResetPassword;
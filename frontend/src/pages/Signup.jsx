@@ -1,5 +1,5 @@
 import React, { useState } from 'react';
-import { Link, useNavigate } from 'react-router-dom';
+import { Link } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 
 const Signup = () => {
@@ -13,8 +13,8 @@ const Signup = () => {
     });
     const [error, setError] = useState('');
     const [loading, setLoading] = useState(false);
+    const [sent, setSent] = useState(false);
     const { signup } = useAuth();
-    const navigate = useNavigate();
 
     const handleChange = (e) => {
         setFormData({
@@ -64,7 +64,7 @@ const Signup = () => {
         );
 
         if (result.success) {
-            navigate('/');
+            setSent(true);
         } else {
             setError(result.error);
         }
@@ -72,6 +72,23 @@ const Signup = () => {
         setLoading(false);
     };
 
+    if (sent) {
+        return (
+            <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
+                <div className="max-w-md w-full space-y-6 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800 text-center">
+                    <h2 className="text-3xl font-bold text-white tracking-tight">Check your email</h2>
+                    <p className="text-sm text-gray-400">
+                        We sent a verification link to <span className="text-gray-200">{formData.email}</span>.
+                        Open it to finish creating your account.
+                    </p>
+                    <Link to="/login" className="inline-block font-semibold text-green-500 hover:text-green-400 transition">
+                        Back to sign in
+                    </Link>
+                </div>
+            </div>
+        );
+    }
+
     return (
         <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
             <div className="max-w-md w-full space-y-8 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800">
@@ -0,0 +1,55 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
+import { useAuth } from '../context/AuthContext';
+
+const VerifyEmail = () => {
+    const [searchParams] = useSearchParams();
+    const [error, setError] = useState('');
+    const { verifyEmail } = useAuth();
+    const navigate = useNavigate();
+    // Tokens are single-use, so don't send it twice under StrictMode
+    const attempted = useRef(false);
+
+    useEffect(() => {
+        if (attempted.current) {
+            return;
+        }
+        attempted.current = true;
+
+        const token = searchParams.get('token');
+        if (!token) {
+            setError('This verification link is incomplete');
+            return;
+        }
+        verifyEmail(token).then((result) => {
+            if (result.success) {
+                navigate('/', { replace: true });
+            } else {
+                setError(result.error);
+            }
+        });
+    }, []);
+
+    return (
+        <div className="min-h-screen flex items-center justify-center bg-black py-12 px-4 sm:px-6 lg:px-8">
+            <div className="max-w-md w-full space-y-6 bg-neutral-900 p-10 rounded-2xl shadow-xl border border-neutral-800 text-center">
+                <h2 className="text-3xl font-bold text-white tracking-tight">
+                    {error ? 'Verification failed' : 'Verifying your email...'}
+                </h2>
+                {error && (
+                    <>
+                        <p className="text-sm text-red-400">{error}</p>
+                        <p className="text-sm text-gray-400">
+                            Sign in to request a new link.
+                        </p>
+                        <Link to="/login" className="inline-block font-semibold text-green-500 hover:text-green-400 transition">
+                            Back to sign in
+                        </Link>
+                    </>
+                )}
+            </div>
+        </div>
+    );
+};
+
+export default VerifyEmail;